@@ -2,17 +2,15 @@ package main
 
 import (
 	"log"
-	"strings"
 	"vidit/internal/database"
+	"vidit/internal/fetcher"
+	"vidit/internal/minhash"
 	"vidit/internal/models"
 
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
 
-// Use the NEW threshold
-const ThresholdDedup = 0.35
-
 // Light version of Article for processing
 type LightArticle struct {
 	ID     uint
@@ -29,6 +27,13 @@ func main() {
 	}
 	database.DB = db
 
+	// Same threshold the fetcher uses to group stories
+	cfg, err := fetcher.LoadRankingConfig(db)
+	if err != nil {
+		log.Fatalf("Failed to load ranking configuration: %v", err)
+	}
+	rs := &fetcher.RankingService{Config: &cfg}
+
 	log.Println("🔄 Loading ALL articles from database...")
 	var articles []models.Article
 	// Order by Score DESC is CRITICAL so we keep the best one
@@ -49,38 +54,33 @@ func main() {
 			ID:     a.ID,
 			Title:  a.Title,
 			Score:  a.Score,
-			Tokens: tokenize(a.Title),
+			Tokens: rs.Tokenize(a.Title),
 		}
 	}
 
 	// Deduplication Logic
 	// Since we are ordered by Score DESC, we iterate and build a "kept" list.
 	// If a candidate matches any in "kept", we mark it for deletion.
-	// O(N^2) is painful for 4000 items (16M checks).
-	// But 16M token-set intersections is doable in Go in seconds/minutes.
-	// We can parallelize? No, dedup is serial by nature (depends on who was kept).
-	// But we can check against "kept" list in parallel?
+	// Kept titles go into a MinHash LSH index, so each candidate is only compared
+	// with the few kept articles likely to be similar instead of all of them.
 
 	keptIndices := make([]int, 0, len(articles))
 	idsToDelete := make([]uint, 0)
+	index := minhash.NewIndex(minhash.DefaultBands, minhash.DefaultRows)
 
-	log.Println("⚡️ Finding duplicates (this might take a moment)...")
+	log.Println("⚡️ Finding duplicates...")
 
 	for i := 0; i < len(lightArticles); i++ {
 		candidate := lightArticles[i]
+		sig := index.Sign(candidate.Tokens)
 		isDuplicate := false
 
-		// Check against already kept articles
-		// Optimization: Check only against kept articles that are somewhat recent or related?
-		// Global check is safer.
+		// Index IDs follow keptIndices
+		for _, k := range index.Candidates(sig) {
+			kept := lightArticles[keptIndices[k]]
 
-		for _, keptIdx := range keptIndices {
-			kept := lightArticles[keptIdx]
-
-			// Quick length check optimization (if one title is 3x longer, Jaccard < 0.4 implied? Not always)
-
-			sim := jaccardQuick(candidate.Tokens, kept.Tokens)
-			if sim > ThresholdDedup {
+			sim := rs.Similarity(candidate.Tokens, kept.Tokens)
+			if sim > cfg.ThresholdDedup {
 				isDuplicate = true
 				break
 			}
@@ -89,6 +89,7 @@ func main() {
 		if isDuplicate {
 			idsToDelete = append(idsToDelete, candidate.ID)
 		} else {
+			index.Insert(sig)
 			keptIndices = append(keptIndices, i)
 		}
 
@@ -116,32 +117,3 @@ func main() {
 		log.Println("✅ Cleanup complete.")
 	}
 }
-
-// Helpers (Duplicated to be standalone)
-func tokenize(text string) map[string]bool {
-	tokens := make(map[string]bool)
-	words := strings.Fields(strings.ToLower(text))
-	for _, w := range words {
-		w = strings.TrimFunc(w, func(r rune) bool {
-			return !((r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r > 127)
-		})
-		if len(w) > 2 {
-			tokens[w] = true
-		}
-	}
-	return tokens
-}
-
-func jaccardQuick(set1, set2 map[string]bool) float64 {
-	if len(set1) == 0 || len(set2) == 0 {
-		return 0.0
-	}
-	intersection := 0
-	for word := range set1 {
-		if set2[word] {
-			intersection++
-		}
-	}
-	union := len(set1) + len(set2) - intersection
-	return float64(intersection) / float64(union)
-}
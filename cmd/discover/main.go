@@ -0,0 +1,128 @@
+// Command discover finds the feeds of a site and prints them ranked, ready to save:
+//
+//	go run ./cmd/discover https://www.emol.com
+//	go run ./cmd/discover -json www.emol.com                # candidate feeds as JSON
+//	go run ./cmd/discover -save 1,3 -category latam emol.com # save candidates #1 and #3
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+	"vidit/internal/database"
+	"vidit/internal/fetcher"
+	"vidit/internal/models"
+
+	"gorm.io/gorm"
+)
+
+func main() {
+	asJSON := flag.Bool("json", false, "Print the candidates as JSON")
+	save := flag.String("save", "", "Comma-separated numbers of the candidates to save")
+	category := flag.String("category", "", "Category of the saved feeds")
+	country := flag.String("country", "", "Country of the saved feeds (guessed from the domain otherwise)")
+	flag.Parse()
+
+	if flag.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "usage: discover [-json] [-save 1,2] [-category c] [-country CC] <site URL>")
+		os.Exit(2)
+	}
+
+	candidates, err := fetcher.NewService().Discover(context.Background(), flag.Arg(0))
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+
+	for i := range candidates {
+		if *category != "" {
+			candidates[i].Feed.Category = strings.ToLower(*category)
+		}
+		if *country != "" {
+			candidates[i].Feed.Country = strings.ToUpper(*country)
+		}
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(candidates)
+	} else {
+		printCandidates(candidates)
+	}
+
+	if *save != "" {
+		saveCandidates(candidates, *save)
+	}
+}
+
+func printCandidates(candidates []fetcher.Candidate) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "#\tOK\tTYPE\tITEMS\tNEWEST\tSCORE\tORIGIN\tURL\tNAME")
+	for i, c := range candidates {
+		ok, newest := "❌", "—"
+		if c.Works() {
+			ok = "✅"
+		}
+		if c.Newest != nil {
+			newest = time.Since(*c.Newest).Round(time.Minute).String()
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%d\t%s\t%.2f\t%s\t%s\t%s\n",
+			i+1, ok, c.Feed.Type, c.Items, newest, c.Score, c.Origin, c.Feed.URL, c.Feed.Name)
+	}
+	w.Flush()
+}
+
+// saveCandidates stores the chosen candidates, skipping URLs that already have a feed
+func saveCandidates(candidates []fetcher.Candidate, choice string) {
+	dbConfig := database.Config{
+		Host:     getEnv("DB_HOST", "localhost"),
+		Port:     getEnv("DB_PORT", "5432"),
+		User:     getEnv("DB_USER", "postgres"),
+		Password: getEnv("DB_PASSWORD", "postgres"),
+		DBName:   getEnv("DB_NAME", "vidit"),
+		SSLMode:  getEnv("DB_SSLMODE", "disable"),
+	}
+	if err := database.Connect(dbConfig); err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+
+	for _, part := range strings.Split(choice, ",") {
+		n, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil || n < 1 || n > len(candidates) {
+			log.Printf("⚠️  No candidate #%s", strings.TrimSpace(part))
+			continue
+		}
+		feed := candidates[n-1].Feed
+
+		var existing models.Feed
+		err = database.DB.Unscoped().Where("url = ?", feed.URL).First(&existing).Error
+		if err == nil {
+			log.Printf("⏭️  %s already exists as %s", feed.URL, existing.Name)
+			continue
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			log.Fatalf("❌ %v", err)
+		}
+
+		if err := database.DB.Create(&feed).Error; err != nil {
+			log.Printf("❌ Could not save %s: %v", feed.URL, err)
+			continue
+		}
+		log.Printf("✅ Added: %s (%s, %s)", feed.Name, feed.Type, feed.URL)
+	}
+}
+
+func getEnv(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return def
+}
@@ -0,0 +1,127 @@
+// fake_mastodon is a tiny stand-in for a Mastodon instance, to try the trends card
+// and the publisher without touching a real account:
+//
+//	go run ./cmd/fake_mastodon
+//	MASTODON_SERVER=http://localhost:4000 MASTODON_ACCESS_TOKEN=test MASTODON_PUBLISH=true go run ./cmd/server
+//
+// Posted statuses are logged, listed at GET /posts and served back in every hashtag timeline.
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+type status struct {
+	ID         string    `json:"id"`
+	URL        string    `json:"url"`
+	Content    string    `json:"content"`
+	Visibility string    `json:"visibility"`
+	Language   string    `json:"language"`
+	Sensitive  bool      `json:"sensitive"`
+	CreatedAt  time.Time `json:"created_at"`
+	Account    account   `json:"account"`
+}
+
+type account struct {
+	ID          string `json:"id"`
+	Username    string `json:"username"`
+	DisplayName string `json:"display_name"`
+}
+
+var (
+	mu       sync.Mutex
+	statuses []status
+	bot      = account{ID: "1", Username: "vidit", DisplayName: "Vidit"}
+)
+
+func main() {
+	port := os.Getenv("FAKE_MASTODON_PORT")
+	if port == "" {
+		port = "4000"
+	}
+	token := os.Getenv("FAKE_MASTODON_TOKEN") // Empty accepts any token
+
+	http.HandleFunc("/api/v1/statuses", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		auth := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if auth == "" || token != "" && auth != token {
+			writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "The access token is invalid"})
+			return
+		}
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		text := r.PostForm.Get("status")
+		if text == "" {
+			writeJSON(w, http.StatusUnprocessableEntity, map[string]string{"error": "Validation failed: Text can't be blank"})
+			return
+		}
+		if n := len([]rune(text)); n > 500 {
+			writeJSON(w, http.StatusUnprocessableEntity, map[string]string{"error": fmt.Sprintf("Validation failed: Text character limit of 500 exceeded (%d)", n)})
+			return
+		}
+
+		mu.Lock()
+		id := fmt.Sprint(len(statuses) + 1)
+		s := status{
+			ID:         id,
+			URL:        "http://localhost:" + port + "/@vidit/" + id,
+			Content:    "<p>" + strings.ReplaceAll(text, "\n", "<br>") + "</p>",
+			Visibility: r.PostForm.Get("visibility"),
+			Language:   r.PostForm.Get("language"),
+			CreatedAt:  time.Now(),
+			Account:    bot,
+		}
+		statuses = append(statuses, s)
+		mu.Unlock()
+
+		log.Printf("🐘 Status %s (%s, %d chars):\n%s\n", id, s.Visibility, len([]rune(text)), text)
+		writeJSON(w, http.StatusOK, s)
+	})
+
+	http.HandleFunc("/api/v1/timelines/tag/", func(w http.ResponseWriter, r *http.Request) {
+		tag := strings.TrimPrefix(r.URL.Path, "/api/v1/timelines/tag/")
+		log.Printf("🔎 Timeline for #%s", tag)
+
+		mu.Lock()
+		timeline := []status{{
+			ID:        "0",
+			Content:   "<p>Post de ejemplo sobre #" + tag + "</p>",
+			Language:  "es",
+			CreatedAt: time.Now(),
+			Account:   account{ID: "2", Username: "ejemplo", DisplayName: "Cuenta de ejemplo"},
+		}}
+		for i := len(statuses) - 1; i >= 0; i-- {
+			timeline = append(timeline, statuses[i])
+		}
+		mu.Unlock()
+
+		writeJSON(w, http.StatusOK, timeline)
+	})
+
+	http.HandleFunc("/posts", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		writeJSON(w, http.StatusOK, statuses)
+	})
+
+	log.Printf("🐘 Fake Mastodon listening on http://localhost:%s\n", port)
+	log.Fatal(http.ListenAndServe(":"+port, nil))
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(v)
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"log"
 	"vidit/internal/database"
 	"vidit/internal/fetcher"
@@ -17,11 +18,15 @@ func main() {
 	}
 	database.DB = db
 
+	if _, err := fetcher.LoadRankingConfig(db); err != nil {
+		log.Fatalf("Failed to load ranking configuration: %v", err)
+	}
+
 	log.Println("🔄 Forcing full feed fetch and ranking...")
 
 	service := fetcher.NewService()
 	// This will fetch fresh content and trigger RankAndDedup
-	if err := service.FetchAllFeeds(database.DB); err != nil {
+	if err := service.FetchAllFeeds(context.Background(), database.DB); err != nil {
 		log.Fatal(err)
 	}
 
@@ -21,7 +21,7 @@ func main() {
 
 	log.Println("🔄 Loading ALL articles from database...")
 	var articles []models.Article
-	if err := db.Find(&articles).Error; err != nil {
+	if err := db.Preload("Feed").Find(&articles).Error; err != nil {
 		log.Fatal(err)
 	}
 	log.Printf("🔹 Loaded %d articles.\n", len(articles))
@@ -30,27 +30,17 @@ func main() {
 		return
 	}
 
-	// Initialize Ranking Service
+	// Initialize Ranking Service with the shared configuration
+	if _, err := fetcher.LoadRankingConfig(db); err != nil {
+		log.Fatalf("Failed to load ranking configuration: %v", err)
+	}
 	rs := &fetcher.RankingService{}
 
 	// Run Ranking Algorithm
-	// Note: We are strictly updating scores here.
-	// RankAndDedup returns the "kept" articles.
-	// For this migration, we want to update the scores of the kept ones.
-	// What about the ones that are dropped (deduplicated)?
-	// Ideally we should mark them as deleted or low score?
-	// For now, let's just update the items returned by RankAndDedup.
-	// The ones NOT returned will just keep their old score? That's bad.
-	//
-	// Better approach: Calculate scores manually for ALL, then maybe Dedup?
-	// The RankingService.RankAndDedup does both.
-	// Let's modify the approach:
-	// We will use RankAndDedup to get the "Clean List".
-	// The Clean List will have updated scores.
-	// effectively, we can update these.
-
-	finalArticles := rs.RankAndDedup(articles)
-	log.Printf("✨ Recalculated scores. New count (after in-memory dedup): %d\n", len(finalArticles))
+	// Rank scores every article without dropping near-duplicates
+	// (those are grouped into stories by the fetcher instead).
+	finalArticles := rs.Rank(articles)
+	log.Printf("✨ Recalculated scores for %d articles\n", len(finalArticles))
 
 	// Batch Update
 	batchSize := 100
@@ -59,7 +49,7 @@ func main() {
 	// We only want to update 'score'.
 	result := db.Clauses(clause.OnConflict{
 		Columns:   []clause.Column{{Name: "url"}},
-		DoUpdates: clause.AssignmentColumns([]string{"score"}),
+		DoUpdates: clause.AssignmentColumns([]string{"score", "cluster_count"}),
 	}).CreateInBatches(&finalArticles, batchSize)
 
 	if result.Error != nil {
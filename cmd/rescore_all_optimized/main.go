@@ -2,12 +2,11 @@ package main
 
 import (
 	"log"
-	"math"
 	"runtime"
-	"strings"
 	"sync"
-	"time"
 	"vidit/internal/database"
+	"vidit/internal/fetcher"
+	"vidit/internal/minhash"
 	"vidit/internal/models"
 
 	"gorm.io/driver/postgres"
@@ -15,19 +14,6 @@ import (
 	"gorm.io/gorm/clause"
 )
 
-// Constants duplicadas para evitar importar fetcher y tener dependencias circulares si modifico fetcher
-const (
-	WeightRSS     = 1.5
-	WeightSitemap = 1.2
-	WeightAPI     = 1.0
-
-	WeightCluster = 2.5
-	GravityDecay  = 1.8
-
-	ThresholdCluster = 0.4
-	ThresholdDedup   = 0.85
-)
-
 func main() {
 	dsn := "host=localhost user=postgres password=postgres dbname=vidit port=5432 sslmode=disable"
 	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
@@ -36,6 +22,13 @@ func main() {
 	}
 	database.DB = db
 
+	// Same parameters as the server
+	cfg, err := fetcher.LoadRankingConfig(db)
+	if err != nil {
+		log.Fatalf("Failed to load ranking configuration: %v", err)
+	}
+	rs := &fetcher.RankingService{Config: &cfg}
+
 	log.Println("🔄 Loading ALL articles from database...")
 	var articles []models.Article
 	// Need to preload Feed to get Type
@@ -52,13 +45,20 @@ func main() {
 	log.Println("⚡️ Pre-computing tokens...")
 	articleTokens := make([]map[string]bool, len(articles))
 	for i, a := range articles {
-		articleTokens[i] = tokenize(a.Title)
+		articleTokens[i] = rs.Tokenize(a.Title)
 	}
 
-	// 2. CLUSTER ANALYSIS (Parallelized)
+	// 2. CLUSTER ANALYSIS (MinHash LSH candidates, parallelized)
 	log.Println("⚡️ Calculating Clusters...")
 	clusterCounts := make([]int, len(articles))
 
+	index := minhash.NewIndex(minhash.DefaultBands, minhash.DefaultRows)
+	signatures := make([]minhash.Signature, len(articles))
+	for i, t := range articleTokens {
+		signatures[i] = index.Sign(t)
+		index.Insert(signatures[i])
+	}
+
 	workers := runtime.NumCPU()
 	var wg sync.WaitGroup
 	chunkSize := (len(articles) + workers - 1) / workers
@@ -74,13 +74,14 @@ func main() {
 		go func(s, e int) {
 			defer wg.Done()
 			for i := s; i < e; i++ {
-				for j := 0; j < len(articles); j++ {
+				// The index is read-only here, so workers can query it concurrently
+				for _, j := range index.Candidates(signatures[i]) {
 					if i == j {
 						continue
 					}
 					// Jaccard using pre-computed tokens
-					sim := jaccardQuick(articleTokens[i], articleTokens[j])
-					if sim > ThresholdCluster {
+					sim := rs.Similarity(articleTokens[i], articleTokens[j])
+					if sim > cfg.ThresholdCluster {
 						clusterCounts[i]++
 					}
 				}
@@ -92,7 +93,8 @@ func main() {
 	// 3. SCORE CALCULATION
 	log.Println("⚡️ Calculating Scores...")
 	for i := range articles {
-		articles[i].Score = calculateGravity(articles[i], clusterCounts[i])
+		articles[i].ClusterCount = clusterCounts[i]
+		articles[i].Score = rs.Gravity(articles[i], clusterCounts[i])
 	}
 
 	// 4. BATCH SAVE
@@ -105,7 +107,7 @@ func main() {
 
 	result := db.Clauses(clause.OnConflict{
 		Columns:   []clause.Column{{Name: "url"}},
-		DoUpdates: clause.AssignmentColumns([]string{"score"}),
+		DoUpdates: clause.AssignmentColumns([]string{"score", "cluster_count"}),
 	}).CreateInBatches(&updates, batchSize)
 
 	if result.Error != nil {
@@ -114,52 +116,3 @@ func main() {
 
 	log.Println("✅ Optimized Rescoring complete.")
 }
-
-// Helpers
-func tokenize(text string) map[string]bool {
-	tokens := make(map[string]bool)
-	words := strings.Fields(strings.ToLower(text))
-	for _, w := range words {
-		w = strings.TrimFunc(w, func(r rune) bool {
-			return !((r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r > 127)
-		})
-		if len(w) > 2 {
-			tokens[w] = true
-		}
-	}
-	return tokens
-}
-
-func jaccardQuick(set1, set2 map[string]bool) float64 {
-	if len(set1) == 0 || len(set2) == 0 {
-		return 0.0
-	}
-	intersection := 0
-	for word := range set1 {
-		if set2[word] {
-			intersection++
-		}
-	}
-	union := len(set1) + len(set2) - intersection
-	return float64(intersection) / float64(union)
-}
-
-func calculateGravity(article models.Article, clusterCount int) float64 {
-	sourceWeight := WeightRSS
-	switch article.Feed.Type {
-	case "sitemap":
-		sourceWeight = WeightSitemap
-	case "newsapi":
-		sourceWeight = WeightAPI
-	}
-
-	hoursElapsed := time.Since(article.PublishedAt).Hours()
-	if hoursElapsed < 0 {
-		hoursElapsed = 0
-	}
-
-	numerator := sourceWeight + (float64(clusterCount) * WeightCluster)
-	denominator := math.Pow(hoursElapsed+2, GravityDecay)
-
-	return numerator / denominator
-}
@@ -0,0 +1,164 @@
+// Command scrape_rules tries CSS selector rules of a scrape feed and prints the
+// headlines they extract, against a saved HTML page or a live URL:
+//
+//	go run ./cmd/scrape_rules -rules internal/fetcher/testdata/scrape/articles.json -file internal/fetcher/testdata/scrape/articles.html
+//	go run ./cmd/scrape_rules -rules rules.json -url https://www.example.cl/
+//	go run ./cmd/scrape_rules -feed "Emol" -title "h3"
+//
+// Selector flags override the loaded rules, so a rule can be tuned one field at a time.
+// It exits with status 1 when nothing is extracted.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+	"vidit/internal/database"
+	"vidit/internal/fetcher"
+	"vidit/internal/models"
+)
+
+func main() {
+	rulesFile := flag.String("rules", "", "JSON file with the scrape rules")
+	file := flag.String("file", "", "Saved HTML page to scrape")
+	pageURL := flag.String("url", "", "Page to fetch (or base URL for links of -file)")
+	feedName := flag.String("feed", "", "Load the URL and rules of this feed from the database")
+
+	var override models.ScrapeRules
+	flag.StringVar(&override.Item, "item", "", "Item selector")
+	flag.StringVar(&override.Title, "title", "", "Title selector")
+	flag.StringVar(&override.Link, "link", "", "Link selector")
+	flag.StringVar(&override.Date, "date", "", "Date selector")
+	flag.StringVar(&override.DateFormat, "date-format", "", "Go layout of text dates")
+	flag.StringVar(&override.Timezone, "timezone", "", "Timezone of dates without one")
+	flag.StringVar(&override.Summary, "summary", "", "Summary selector")
+	flag.StringVar(&override.Image, "image", "", "Image selector")
+	flag.Parse()
+
+	var rules models.ScrapeRules
+	if *feedName != "" {
+		feed := loadFeed(*feedName)
+		if feed.ScrapeRules != nil {
+			rules = *feed.ScrapeRules
+		}
+		if *pageURL == "" {
+			*pageURL = feed.URL
+		}
+	}
+	if *rulesFile != "" {
+		data, err := os.ReadFile(*rulesFile)
+		if err != nil {
+			log.Fatalf("❌ Could not read rules: %v", err)
+		}
+		if err := json.Unmarshal(data, &rules); err != nil {
+			log.Fatalf("❌ Invalid rules JSON: %v", err)
+		}
+	}
+	merge(&rules, override)
+
+	if err := fetcher.ValidateScrapeRules(&rules); err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+
+	var articles []models.Article
+	switch {
+	case *file != "":
+		f, err := os.Open(*file)
+		if err != nil {
+			log.Fatalf("❌ Could not open page: %v", err)
+		}
+		defer f.Close()
+
+		base := *pageURL
+		if base == "" {
+			base = "https://www.example.cl/"
+		}
+		articles, err = fetcher.ScrapeArticles(f, "text/html", base, rules)
+		if err != nil {
+			log.Fatalf("❌ %v", err)
+		}
+
+	case *pageURL != "":
+		// The real source, so host limits, the user agent and the gossip filter apply
+		feed := models.Feed{Name: *pageURL, URL: *pageURL, Type: "scrape", ScrapeRules: &rules, Fallbacks: []string{}}
+		result := fetcher.NewService().TestFeed(context.Background(), feed)
+		for _, a := range result.Attempts {
+			fmt.Printf("→ %s %s: %d · %d ms\n", a.Strategy, a.URL, a.StatusCode, a.Latency.Milliseconds())
+		}
+		if result.Err != nil {
+			log.Fatalf("❌ %v", result.Err)
+		}
+		articles = result.Articles
+
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	for i, a := range articles {
+		date := "sin fecha"
+		if !a.PublishedAt.IsZero() {
+			date = a.PublishedAt.Format(time.RFC3339)
+		}
+		fmt.Printf("%3d. [%s] %s\n     %s\n", i+1, date, a.Title, a.URL)
+		if a.Summary != "" {
+			fmt.Printf("     %s\n", a.Summary)
+		}
+		if a.ImageURL != "" {
+			fmt.Printf("     🖼️  %s\n", a.ImageURL)
+		}
+	}
+
+	fmt.Printf("\n%d items extracted\n", len(articles))
+	if len(articles) == 0 {
+		os.Exit(1)
+	}
+}
+
+// merge replaces the rules' selectors with the ones given as flags
+func merge(rules *models.ScrapeRules, override models.ScrapeRules) {
+	set := func(dst *string, v string) {
+		if v != "" {
+			*dst = v
+		}
+	}
+	set(&rules.Item, override.Item)
+	set(&rules.Title, override.Title)
+	set(&rules.Link, override.Link)
+	set(&rules.Date, override.Date)
+	set(&rules.DateFormat, override.DateFormat)
+	set(&rules.Timezone, override.Timezone)
+	set(&rules.Summary, override.Summary)
+	set(&rules.Image, override.Image)
+}
+
+func loadFeed(name string) models.Feed {
+	dbConfig := database.Config{
+		Host:     getEnv("DB_HOST", "localhost"),
+		Port:     getEnv("DB_PORT", "5432"),
+		User:     getEnv("DB_USER", "postgres"),
+		Password: getEnv("DB_PASSWORD", "postgres"),
+		DBName:   getEnv("DB_NAME", "vidit"),
+		SSLMode:  getEnv("DB_SSLMODE", "disable"),
+	}
+	if err := database.Connect(dbConfig); err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+
+	var feed models.Feed
+	if err := database.DB.Where("name = ?", name).First(&feed).Error; err != nil {
+		log.Fatalf("❌ Feed %s not found: %v", name, err)
+	}
+	return feed
+}
+
+func getEnv(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return def
+}
@@ -0,0 +1,435 @@
+package main
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"log"
+	"mime"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+	"vidit/internal/database"
+	"vidit/internal/fetcher"
+	"vidit/internal/models"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	"gorm.io/gorm"
+)
+
+var colorHex = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+// FeedInput is the payload accepted when creating or editing a feed
+type FeedInput struct {
+	Name     string `json:"name"`
+	URL      string `json:"url"`
+	Type     string `json:"type"`
+	Category string `json:"category"`
+	Country  string `json:"country"`
+	ColorHex string `json:"color_hex"`
+
+	TrustWeight *float64 `json:"trust_weight"` // Unset keeps the feed's, 1 (neutral) for new feeds; 0 mutes it
+
+	// Ordered fallback sources: null keeps the type's default chain, [] disables them
+	Fallbacks      []string `json:"fallbacks"`
+	AutoSwitchType bool     `json:"auto_switch_type"`
+
+	ScrapeRules *models.ScrapeRules `json:"scrape_rules"` // Required for scrape feeds
+}
+
+// DiscoveredFeed is a discovery candidate, flagged when a feed already uses its URL
+type DiscoveredFeed struct {
+	fetcher.Candidate
+	Exists bool `json:"exists"`
+}
+
+// AdminFeed is a feed as listed by the admin API, including soft-deleted ones
+type AdminFeed struct {
+	models.Feed
+	Deleted bool `json:"deleted"`
+}
+
+// registerAdmin mounts the admin page and API behind HTTP Basic Auth.
+// It is disabled unless ADMIN_PASSWORD is set.
+func registerAdmin(e *echo.Echo) {
+	user := getEnv("ADMIN_USER", "admin")
+	password := os.Getenv("ADMIN_PASSWORD")
+	if password == "" {
+		log.Println("⚠️  ADMIN_PASSWORD not set, admin routes disabled")
+		return
+	}
+
+	admin := e.Group("/admin", middleware.BasicAuth(func(u, p string, c echo.Context) (bool, error) {
+		userOK := subtle.ConstantTimeCompare([]byte(u), []byte(user)) == 1
+		passOK := subtle.ConstantTimeCompare([]byte(p), []byte(password)) == 1
+		return userOK && passOK, nil
+	}), requireJSON)
+
+	admin.GET("", handleAdminPage)
+	admin.GET("/feeds", handleAdminFeeds)
+	admin.POST("/feeds", handleAdminCreateFeed)
+	admin.PUT("/feeds/:id", handleAdminUpdateFeed)
+	admin.DELETE("/feeds/:id", handleAdminDeleteFeed)
+	admin.POST("/feeds/:id/restore", handleAdminRestoreFeed)
+	admin.POST("/feeds/:id/test", handleAdminTestFeed)
+	admin.POST("/feeds/:id/enable", handleAdminEnableFeed)
+	admin.GET("/feeds/:id/type-changes", handleAdminTypeChanges)
+	admin.POST("/discover", handleAdminDiscover)
+	admin.GET("/health", handleAdminHealth)
+	admin.GET("/ranking", handleAdminRanking)
+	admin.PUT("/ranking", handleAdminUpdateRanking)
+	admin.POST("/ranking/reload", handleAdminReloadRanking)
+}
+
+// requireJSON refuses changes whose body isn't declared as JSON. Browsers only send
+// that content type cross-site after a CORS preflight, so another site can't forge
+// admin requests riding on the cached Basic Auth credentials.
+func requireJSON(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		switch c.Request().Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			return next(c)
+		}
+		mediaType, _, _ := mime.ParseMediaType(c.Request().Header.Get(echo.HeaderContentType))
+		if mediaType != echo.MIMEApplicationJSON {
+			return apiError(c, http.StatusUnsupportedMediaType, "admin changes must be sent as application/json")
+		}
+		return next(c)
+	}
+}
+
+func handleAdminPage(c echo.Context) error {
+	return c.Render(http.StatusOK, "admin.html", map[string]interface{}{
+		"FeedTypes": fetcher.SourceNames(),
+	})
+}
+
+func handleAdminFeeds(c echo.Context) error {
+	var feeds []models.Feed
+	if err := database.DB.Unscoped().Order("name ASC").Find(&feeds).Error; err != nil {
+		return apiError(c, http.StatusInternalServerError, "error loading feeds")
+	}
+
+	result := make([]AdminFeed, len(feeds))
+	for i, f := range feeds {
+		result[i] = AdminFeed{Feed: f, Deleted: f.DeletedAt.Valid}
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"data":  result,
+		"total": len(result),
+	})
+}
+
+func handleAdminCreateFeed(c echo.Context) error {
+	var input FeedInput
+	if err := c.Bind(&input); err != nil {
+		return apiError(c, http.StatusBadRequest, "invalid payload")
+	}
+	if err := input.normalize(); err != nil {
+		return apiError(c, http.StatusBadRequest, err.Error())
+	}
+
+	// URL is unique, soft-deleted rows included
+	var existing models.Feed
+	err := database.DB.Unscoped().Where("url = ?", input.URL).First(&existing).Error
+	if err == nil {
+		if existing.DeletedAt.Valid {
+			return apiError(c, http.StatusConflict, "a deleted feed already uses this URL, restore it instead")
+		}
+		return apiError(c, http.StatusConflict, "a feed with this URL already exists")
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return apiError(c, http.StatusInternalServerError, err.Error())
+	}
+
+	feed := models.Feed{}
+	input.apply(&feed)
+	muted := feed.TrustWeight == 0
+	if err := database.DB.Create(&feed).Error; err != nil {
+		return apiError(c, http.StatusInternalServerError, err.Error())
+	}
+	// Create writes the column default (1) in place of a zero weight
+	if muted {
+		if err := database.DB.Model(&feed).Update("trust_weight", 0).Error; err != nil {
+			return apiError(c, http.StatusInternalServerError, err.Error())
+		}
+	}
+
+	log.Printf("✅ Admin created feed %s (%s)", feed.Name, feed.URL)
+	return c.JSON(http.StatusCreated, feed)
+}
+
+func handleAdminUpdateFeed(c echo.Context) error {
+	feed, ok, err := findAdminFeed(c)
+	if !ok {
+		return err
+	}
+
+	var input FeedInput
+	if err := c.Bind(&input); err != nil {
+		return apiError(c, http.StatusBadRequest, "invalid payload")
+	}
+	if err := input.normalize(); err != nil {
+		return apiError(c, http.StatusBadRequest, err.Error())
+	}
+
+	if input.URL != feed.URL {
+		var count int64
+		err := database.DB.Unscoped().Model(&models.Feed{}).Where("url = ? AND id <> ?", input.URL, feed.ID).Count(&count).Error
+		if err != nil {
+			return apiError(c, http.StatusInternalServerError, err.Error())
+		}
+		if count > 0 {
+			return apiError(c, http.StatusConflict, "another feed already uses this URL")
+		}
+	}
+
+	// Only the form's columns: health, schedule and cache ones are written by the fetcher meanwhile
+	input.apply(&feed)
+	if err := database.DB.Unscoped().Model(&feed).Select(feedInputColumns).Updates(&feed).Error; err != nil {
+		return apiError(c, http.StatusInternalServerError, err.Error())
+	}
+	if err := database.DB.Unscoped().First(&feed, feed.ID).Error; err != nil {
+		return apiError(c, http.StatusInternalServerError, err.Error())
+	}
+
+	log.Printf("📝 Admin updated feed %s (%s)", feed.Name, feed.URL)
+	return c.JSON(http.StatusOK, feed)
+}
+
+func handleAdminDeleteFeed(c echo.Context) error {
+	feed, ok, err := findAdminFeed(c)
+	if !ok {
+		return err
+	}
+
+	// Soft delete: articles stay in the archive, the feed just stops being fetched and shown
+	if err := database.DB.Delete(&feed).Error; err != nil {
+		return apiError(c, http.StatusInternalServerError, err.Error())
+	}
+
+	log.Printf("🗑️  Admin deleted feed %s", feed.Name)
+	return c.JSON(http.StatusOK, map[string]string{
+		"status": "Feed deleted",
+	})
+}
+
+func handleAdminRestoreFeed(c echo.Context) error {
+	feed, ok, err := findAdminFeed(c)
+	if !ok {
+		return err
+	}
+
+	if err := database.DB.Unscoped().Model(&feed).Update("deleted_at", nil).Error; err != nil {
+		return apiError(c, http.StatusInternalServerError, err.Error())
+	}
+
+	log.Printf("♻️  Admin restored feed %s", feed.Name)
+	return c.JSON(http.StatusOK, map[string]string{
+		"status": "Feed restored",
+	})
+}
+
+func handleAdminTestFeed(c echo.Context) error {
+	feed, ok, err := findAdminFeed(c)
+	if !ok {
+		return err
+	}
+
+	start := time.Now()
+	service := fetcher.NewService()
+	result := service.TestFeed(c.Request().Context(), feed)
+
+	attempts := make([]map[string]interface{}, len(result.Attempts))
+	for i, a := range result.Attempts {
+		attempts[i] = map[string]interface{}{
+			"strategy":    a.Strategy,
+			"url":         a.URL,
+			"status_code": a.StatusCode,
+			"latency_ms":  a.Latency.Milliseconds(),
+			"item_count":  a.ItemCount,
+		}
+		if a.Err != nil {
+			attempts[i]["error"] = a.Err.Error()
+		}
+	}
+
+	response := map[string]interface{}{
+		"feed":       feed.Name,
+		"strategy":   result.Strategy,
+		"count":      len(result.Articles),
+		"elapsed_ms": time.Since(start).Milliseconds(),
+		"attempts":   attempts,
+	}
+	if result.Err != nil {
+		response["error"] = result.Err.Error()
+	}
+
+	// A small sample is enough to eyeball the result
+	sample := result.Articles
+	if len(sample) > 10 {
+		sample = sample[:10]
+	}
+	response["items"] = sample
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// handleAdminDiscover finds and probes the feeds of a site; nothing is saved
+func handleAdminDiscover(c echo.Context) error {
+	var input struct {
+		URL string `json:"url"`
+	}
+	if err := c.Bind(&input); err != nil || strings.TrimSpace(input.URL) == "" {
+		return apiError(c, http.StatusBadRequest, "url is required")
+	}
+
+	candidates, err := fetcher.NewService().Discover(c.Request().Context(), strings.TrimSpace(input.URL))
+	if err != nil {
+		return apiError(c, http.StatusBadGateway, err.Error())
+	}
+
+	urls := make([]string, len(candidates))
+	for i, cand := range candidates {
+		urls[i] = cand.Feed.URL
+	}
+	var taken []string
+	if len(urls) > 0 {
+		database.DB.Unscoped().Model(&models.Feed{}).Where("url IN ?", urls).Pluck("url", &taken)
+	}
+	exists := make(map[string]bool, len(taken))
+	for _, u := range taken {
+		exists[u] = true
+	}
+
+	result := make([]DiscoveredFeed, len(candidates))
+	for i, cand := range candidates {
+		result[i] = DiscoveredFeed{Candidate: cand, Exists: exists[cand.Feed.URL]}
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"data":  result,
+		"total": len(result),
+	})
+}
+
+// handleAdminTypeChanges lists the fallback switches recorded for a feed, newest first
+func handleAdminTypeChanges(c echo.Context) error {
+	feed, ok, err := findAdminFeed(c)
+	if !ok {
+		return err
+	}
+
+	var changes []models.FeedTypeChange
+	if err := database.DB.Where("feed_id = ?", feed.ID).Order("created_at DESC").Find(&changes).Error; err != nil {
+		return apiError(c, http.StatusInternalServerError, "error loading type changes")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"data":  changes,
+		"total": len(changes),
+	})
+}
+
+// findAdminFeed loads the :id feed, soft-deleted included.
+// When ok is false the error response has already been written.
+func findAdminFeed(c echo.Context) (models.Feed, bool, error) {
+	var feed models.Feed
+
+	id, err := parseID(c)
+	if err != nil {
+		return feed, false, apiError(c, http.StatusBadRequest, "invalid feed id")
+	}
+
+	if err := database.DB.Unscoped().First(&feed, id).Error; err != nil {
+		return feed, false, notFoundOr500(c, err, "feed not found")
+	}
+
+	return feed, true, nil
+}
+
+func (in *FeedInput) normalize() error {
+	in.Name = strings.TrimSpace(in.Name)
+	in.URL = strings.TrimSpace(in.URL)
+	in.Type = strings.ToLower(strings.TrimSpace(in.Type))
+	in.Category = strings.ToLower(strings.TrimSpace(in.Category))
+	in.Country = strings.TrimSpace(in.Country)
+	in.ColorHex = strings.TrimSpace(in.ColorHex)
+
+	if in.Name == "" || in.URL == "" {
+		return errors.New("name and url are required")
+	}
+	if in.Type == "" {
+		in.Type = "rss"
+	}
+	if !fetcher.IsSource(in.Type) {
+		return fmt.Errorf("type must be one of %s", strings.Join(fetcher.SourceNames(), ", "))
+	}
+	if in.Type == "scrape" {
+		if err := fetcher.ValidateScrapeRules(in.ScrapeRules); err != nil {
+			return err
+		}
+	} else {
+		in.ScrapeRules = nil
+	}
+	for i, name := range in.Fallbacks {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if !fetcher.IsSource(name) {
+			return fmt.Errorf("unknown fallback source %q", name)
+		}
+		if name == in.Type {
+			return errors.New("fallbacks cannot include the feed's own type")
+		}
+		in.Fallbacks[i] = name
+	}
+	// NewsAPI feeds use a bare domain, the others a full URL
+	if in.Type != "newsapi" && !strings.HasPrefix(in.URL, "http") {
+		return errors.New("url must start with http:// or https://")
+	}
+	if in.Category == "" {
+		in.Category = "general"
+	}
+	if in.Country == "" {
+		in.Country = "int"
+	} else if len(in.Country) == 2 {
+		in.Country = strings.ToUpper(in.Country)
+	}
+	if in.ColorHex == "" {
+		in.ColorHex = "#3b82f6"
+	}
+	if !colorHex.MatchString(in.ColorHex) {
+		return errors.New("color_hex must look like #rrggbb")
+	}
+	if in.TrustWeight != nil && *in.TrustWeight < 0 {
+		return errors.New("trust_weight cannot be negative")
+	}
+
+	return nil
+}
+
+// feedInputColumns are the columns apply sets
+var feedInputColumns = []string{
+	"name", "url", "type", "category", "country", "color_hex", "trust_weight",
+	"fallbacks", "auto_switch_type", "scrape_rules",
+}
+
+func (in *FeedInput) apply(feed *models.Feed) {
+	feed.Name = in.Name
+	feed.URL = in.URL
+	feed.Type = in.Type
+	feed.Category = in.Category
+	feed.Country = in.Country
+	feed.ColorHex = in.ColorHex
+	if in.TrustWeight != nil {
+		feed.TrustWeight = *in.TrustWeight
+	} else if feed.ID == 0 {
+		feed.TrustWeight = 1
+	}
+	feed.Fallbacks = in.Fallbacks
+	feed.AutoSwitchType = in.AutoSwitchType
+	feed.ScrapeRules = in.ScrapeRules
+}
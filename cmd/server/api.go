@@ -0,0 +1,321 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"vidit/internal/database"
+	"vidit/internal/models"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultPerPage = 50
+	maxPerPage     = 200
+)
+
+// PageResponse is the envelope for every paginated /api/v1 listing
+type PageResponse struct {
+	Data    interface{} `json:"data"`
+	Page    int         `json:"page"`
+	PerPage int         `json:"per_page"`
+	Total   int64       `json:"total"`
+}
+
+// registerAPI mounts the versioned JSON API on the router
+func registerAPI(e *echo.Echo) {
+	api := e.Group("/api/v1")
+
+	api.GET("/articles", handleAPIArticles)
+	api.GET("/articles/:id", handleAPIArticle)
+	api.GET("/feeds", handleAPIFeeds)
+	api.GET("/feeds/:id/articles", handleAPIFeedArticles)
+	api.GET("/stories", handleAPIStories)
+	api.GET("/stories/:id", handleAPIStory)
+}
+
+func handleAPIArticles(c echo.Context) error {
+	return listArticles(c, 0)
+}
+
+func handleAPIFeedArticles(c echo.Context) error {
+	feedID, err := parseID(c)
+	if err != nil {
+		return apiError(c, http.StatusBadRequest, "invalid feed id")
+	}
+
+	var feed models.Feed
+	if err := database.DB.First(&feed, feedID).Error; err != nil {
+		return notFoundOr500(c, err, "feed not found")
+	}
+
+	return listArticles(c, feed.ID)
+}
+
+// listArticles applies the shared filters (category, country, feed_id, since, until, hours, min_score)
+func listArticles(c echo.Context, feedID uint) error {
+	page, perPage := pagination(c)
+
+	query := database.DB.Model(&models.Article{}).
+		Joins("JOIN feeds ON feeds.id = articles.feed_id AND feeds.deleted_at IS NULL")
+
+	if feedID == 0 && c.QueryParam("feed_id") != "" {
+		id, err := strconv.ParseUint(c.QueryParam("feed_id"), 10, 64)
+		if err != nil {
+			return apiError(c, http.StatusBadRequest, "invalid feed_id")
+		}
+		feedID = uint(id)
+	}
+	if feedID != 0 {
+		query = query.Where("articles.feed_id = ?", feedID)
+	}
+
+	if category := c.QueryParam("category"); category != "" {
+		query = query.Where("feeds.category = ?", category)
+	}
+	if country := c.QueryParam("country"); country != "" {
+		query = query.Where("UPPER(feeds.country) = ?", strings.ToUpper(country))
+	}
+
+	since, until, untilDay, err := timeWindow(c)
+	if err != nil {
+		return apiError(c, http.StatusBadRequest, err.Error())
+	}
+	if !since.IsZero() {
+		query = query.Where("articles.published_at >= ?", since)
+	}
+	if !until.IsZero() {
+		query = query.Where(untilCondition("articles.published_at", until, untilDay))
+	}
+
+	if raw := c.QueryParam("min_score"); raw != "" {
+		minScore, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return apiError(c, http.StatusBadRequest, "invalid min_score")
+		}
+		query = query.Where("articles.score >= ?", minScore)
+	}
+
+	// New session so Count doesn't leak into the page query
+	query = query.Session(&gorm.Session{})
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return apiError(c, http.StatusInternalServerError, "error counting articles")
+	}
+
+	var articles []models.Article
+	err = query.
+		Preload("Feed").
+		Order("articles.score DESC, articles.published_at DESC").
+		Offset((page - 1) * perPage).
+		Limit(perPage).
+		Find(&articles).Error
+	if err != nil {
+		return apiError(c, http.StatusInternalServerError, "error loading articles")
+	}
+
+	return c.JSON(http.StatusOK, PageResponse{
+		Data:    articles,
+		Page:    page,
+		PerPage: perPage,
+		Total:   total,
+	})
+}
+
+func handleAPIArticle(c echo.Context) error {
+	id, err := parseID(c)
+	if err != nil {
+		return apiError(c, http.StatusBadRequest, "invalid article id")
+	}
+
+	var article models.Article
+	err = database.DB.Preload("Feed").
+		Joins("JOIN feeds ON feeds.id = articles.feed_id AND feeds.deleted_at IS NULL").
+		First(&article, "articles.id = ?", id).Error
+	if err != nil {
+		return notFoundOr500(c, err, "article not found")
+	}
+
+	return c.JSON(http.StatusOK, article)
+}
+
+func handleAPIFeeds(c echo.Context) error {
+	var feeds []models.Feed
+
+	query := database.DB.Order("name ASC")
+	if category := c.QueryParam("category"); category != "" {
+		query = query.Where("category = ?", category)
+	}
+	if country := c.QueryParam("country"); country != "" {
+		query = query.Where("UPPER(country) = ?", strings.ToUpper(country))
+	}
+
+	if err := query.Find(&feeds).Error; err != nil {
+		return apiError(c, http.StatusInternalServerError, "error loading feeds")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"data":  feeds,
+		"total": len(feeds),
+	})
+}
+
+func handleAPIStories(c echo.Context) error {
+	page, perPage := pagination(c)
+
+	query := database.DB.Model(&models.Story{}).
+		Joins("JOIN articles ON articles.id = stories.lead_article_id AND articles.deleted_at IS NULL").
+		Joins("JOIN feeds ON feeds.id = articles.feed_id AND feeds.deleted_at IS NULL")
+
+	since, until, untilDay, err := timeWindow(c)
+	if err != nil {
+		return apiError(c, http.StatusBadRequest, err.Error())
+	}
+	if !since.IsZero() {
+		query = query.Where("stories.last_seen_at >= ?", since)
+	}
+	if !until.IsZero() {
+		query = query.Where(untilCondition("stories.first_seen_at", until, untilDay))
+	}
+
+	if raw := c.QueryParam("min_score"); raw != "" {
+		minScore, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return apiError(c, http.StatusBadRequest, "invalid min_score")
+		}
+		query = query.Where("stories.score >= ?", minScore)
+	}
+
+	// New session so Count doesn't leak into the page query
+	query = query.Session(&gorm.Session{})
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return apiError(c, http.StatusInternalServerError, "error counting stories")
+	}
+
+	var stories []models.Story
+	err = query.
+		Preload("LeadArticle.Feed").
+		Order("stories.score DESC, stories.last_seen_at DESC").
+		Offset((page - 1) * perPage).
+		Limit(perPage).
+		Find(&stories).Error
+	if err != nil {
+		return apiError(c, http.StatusInternalServerError, "error loading stories")
+	}
+
+	return c.JSON(http.StatusOK, PageResponse{
+		Data:    stories,
+		Page:    page,
+		PerPage: perPage,
+		Total:   total,
+	})
+}
+
+func handleAPIStory(c echo.Context) error {
+	id, err := parseID(c)
+	if err != nil {
+		return apiError(c, http.StatusBadRequest, "invalid story id")
+	}
+
+	var story models.Story
+	err = database.DB.
+		Preload("LeadArticle.Feed").
+		Preload("Articles", func(tx *gorm.DB) *gorm.DB {
+			return tx.Order("articles.score DESC")
+		}).
+		Preload("Articles.Feed").
+		First(&story, id).Error
+	if err != nil {
+		return notFoundOr500(c, err, "story not found")
+	}
+
+	return c.JSON(http.StatusOK, story)
+}
+
+// pagination reads ?page= and ?per_page= with sane bounds
+func pagination(c echo.Context) (int, int) {
+	page, err := strconv.Atoi(c.QueryParam("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	perPage, err := strconv.Atoi(c.QueryParam("per_page"))
+	if err != nil || perPage < 1 {
+		perPage = defaultPerPage
+	}
+	if perPage > maxPerPage {
+		perPage = maxPerPage
+	}
+
+	return page, perPage
+}
+
+// timeWindow reads ?since= and ?until= (RFC3339 or YYYY-MM-DD) or ?hours= (last N hours)
+// timeWindow reads hours, since and until. untilDay is set when until is a bare
+// date, which then covers that whole day.
+func timeWindow(c echo.Context) (since, until time.Time, untilDay bool, err error) {
+	if raw := c.QueryParam("hours"); raw != "" {
+		hours, err := strconv.Atoi(raw)
+		if err != nil || hours < 1 {
+			return since, until, false, errors.New("invalid hours")
+		}
+		since = time.Now().Add(-time.Duration(hours) * time.Hour)
+	}
+
+	if raw := c.QueryParam("since"); raw != "" {
+		if since, _, err = parseTimeParam(raw); err != nil {
+			return since, until, false, errors.New("invalid since (use RFC3339 or YYYY-MM-DD)")
+		}
+	}
+
+	if raw := c.QueryParam("until"); raw != "" {
+		if until, untilDay, err = parseTimeParam(raw); err != nil {
+			return since, until, false, errors.New("invalid until (use RFC3339 or YYYY-MM-DD)")
+		}
+	}
+
+	return since, until, untilDay, nil
+}
+
+// untilCondition compares column with the end of the window: up to a timestamp
+// included, or before the day after a bare date
+func untilCondition(column string, until time.Time, untilDay bool) (string, time.Time) {
+	if untilDay {
+		return column + " < ?", until.AddDate(0, 0, 1)
+	}
+	return column + " <= ?", until
+}
+
+// parseTimeParam reads an RFC3339 timestamp or a YYYY-MM-DD date (dateOnly)
+func parseTimeParam(raw string) (t time.Time, dateOnly bool, err error) {
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		return t, false, nil
+	}
+	t, err = time.ParseInLocation("2006-01-02", raw, time.Local)
+	return t, true, err
+}
+
+func parseID(c echo.Context) (uint, error) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	return uint(id), err
+}
+
+func notFoundOr500(c echo.Context, err error, notFound string) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return apiError(c, http.StatusNotFound, notFound)
+	}
+	return apiError(c, http.StatusInternalServerError, err.Error())
+}
+
+func apiError(c echo.Context, status int, message string) error {
+	return c.JSON(status, map[string]string{
+		"error": message,
+	})
+}
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+	"vidit/internal/database"
+	"vidit/internal/fetcher"
+	"vidit/internal/models"
+
+	"github.com/labstack/echo/v4"
+)
+
+const dayLayout = "2006-01-02"
+
+// archiveLocation decides where a day starts and ends (ARCHIVE_TZ, an IANA zone name
+// that Postgres also understands)
+var archiveLocation = loadArchiveLocation()
+
+func loadArchiveLocation() *time.Location {
+	name := getEnv("ARCHIVE_TZ", "America/Santiago")
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		log.Printf("⚠️  Unknown ARCHIVE_TZ %q, using UTC: %v\n", name, err)
+		return time.UTC
+	}
+	return loc
+}
+
+var spanishMonths = [...]string{"", "Enero", "Febrero", "Marzo", "Abril", "Mayo", "Junio", "Julio",
+	"Agosto", "Septiembre", "Octubre", "Noviembre", "Diciembre"}
+
+var spanishWeekdays = [...]string{"Domingo", "Lunes", "Martes", "Miércoles", "Jueves", "Viernes", "Sábado"}
+
+// ArchiveDay is a cell of the archive calendar; Day is 0 for the padding cells
+type ArchiveDay struct {
+	Day   int
+	Date  string
+	Count int64
+}
+
+// ArchiveMonth is one month of the archive calendar, in weeks starting on Monday
+type ArchiveMonth struct {
+	Label string
+	Total int64
+	Weeks [][]ArchiveDay
+}
+
+// registerArchive mounts the calendar and the per-day front pages
+func registerArchive(e *echo.Echo) {
+	e.GET("/archive", handleArchiveIndex)
+	e.GET("/archive/:date", handleArchiveDay)
+}
+
+// handleArchiveDay rebuilds the front page as it looked at the end of a day:
+// the articles of the previous frontPageWindow, clustered and ranked as of that moment.
+// Today's page is rebuilt as of now.
+func handleArchiveDay(c echo.Context) error {
+	day, err := time.ParseInLocation(dayLayout, c.Param("date"), archiveLocation)
+	if err != nil {
+		return c.String(http.StatusBadRequest, "Fecha inválida (usa AAAA-MM-DD)")
+	}
+
+	now := time.Now()
+	end := day.AddDate(0, 0, 1)
+	if day.After(now) {
+		return c.String(http.StatusNotFound, "Esa fecha aún no llega")
+	}
+	if end.After(now) {
+		end = now
+	}
+
+	var articles []models.Article
+	err = database.DB.
+		Joins("JOIN feeds ON feeds.id = articles.feed_id AND feeds.deleted_at IS NULL").
+		Preload("Feed").
+		Where("articles.published_at >= ? AND articles.published_at < ?", end.Add(-frontPageWindow), end).
+		Find(&articles).Error
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "Error loading articles")
+	}
+
+	rs := &fetcher.RankingService{Now: end}
+	stories := rs.SnapshotStories(articles)
+	if len(stories) > frontPageSize {
+		stories = stories[:frontPageSize]
+	}
+
+	prevDay, nextDay := archiveNeighbours(day, day.AddDate(0, 0, 1))
+
+	return c.Render(http.StatusOK, "index.html", map[string]interface{}{
+		"Stories":   stories,
+		"Count":     len(stories),
+		"Now":       end,
+		"Archive":   true,
+		"DateLabel": spanishDay(day),
+		"PrevDay":   prevDay,
+		"NextDay":   nextDay,
+	})
+}
+
+// archiveNeighbours returns the closest days before start and from end on that have articles
+func archiveNeighbours(start, end time.Time) (prev, next string) {
+	var row struct {
+		Prev *time.Time
+		Next *time.Time
+	}
+	database.DB.Raw(`SELECT
+			(SELECT MAX(published_at) FROM articles WHERE deleted_at IS NULL AND published_at < ?) AS prev,
+			(SELECT MIN(published_at) FROM articles WHERE deleted_at IS NULL AND published_at >= ? AND published_at <= NOW()) AS next`,
+		start, end).Scan(&row)
+
+	if row.Prev != nil {
+		prev = row.Prev.In(archiveLocation).Format(dayLayout)
+	}
+	if row.Next != nil {
+		next = row.Next.In(archiveLocation).Format(dayLayout)
+	}
+	return prev, next
+}
+
+// handleArchiveIndex shows a calendar of every month with articles, newest first
+func handleArchiveIndex(c echo.Context) error {
+	var rows []struct {
+		Day   string
+		Count int64
+	}
+	err := database.DB.Raw(`
+		SELECT to_char(published_at AT TIME ZONE ?, 'YYYY-MM-DD') AS day, COUNT(*) AS count
+		FROM articles
+		WHERE deleted_at IS NULL AND published_at <= NOW()
+		GROUP BY day
+		ORDER BY day DESC`, archiveLocation.String()).Scan(&rows).Error
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "Error loading archive")
+	}
+
+	counts := make(map[string]int64, len(rows))
+	var months []ArchiveMonth
+	seen := make(map[string]bool)
+	for _, r := range rows {
+		counts[r.Day] = r.Count
+	}
+	for _, r := range rows {
+		day, err := time.ParseInLocation(dayLayout, r.Day, archiveLocation)
+		if err != nil || seen[day.Format("2006-01")] {
+			continue
+		}
+		seen[day.Format("2006-01")] = true
+		months = append(months, archiveMonth(day.Year(), day.Month(), counts))
+	}
+
+	return c.Render(http.StatusOK, "archive.html", map[string]interface{}{
+		"Months": months,
+		"Days":   len(rows),
+	})
+}
+
+// archiveMonth lays out a month in Monday-first weeks
+func archiveMonth(year int, month time.Month, counts map[string]int64) ArchiveMonth {
+	m := ArchiveMonth{Label: fmt.Sprintf("%s %d", spanishMonths[month], year)}
+
+	first := time.Date(year, month, 1, 0, 0, 0, 0, archiveLocation)
+	week := make([]ArchiveDay, (int(first.Weekday())+6)%7) // Padding before the 1st
+
+	for d := first; d.Month() == month; d = d.AddDate(0, 0, 1) {
+		date := d.Format(dayLayout)
+		week = append(week, ArchiveDay{Day: d.Day(), Date: date, Count: counts[date]})
+		m.Total += counts[date]
+		if len(week) == 7 {
+			m.Weeks = append(m.Weeks, week)
+			week = nil
+		}
+	}
+	if len(week) > 0 {
+		for len(week) < 7 {
+			week = append(week, ArchiveDay{})
+		}
+		m.Weeks = append(m.Weeks, week)
+	}
+	return m
+}
+
+// spanishDay formats a day like "Lunes 6 de Octubre de 2025"
+func spanishDay(t time.Time) string {
+	return fmt.Sprintf("%s %d de %s de %d", spanishWeekdays[t.Weekday()], t.Day(), spanishMonths[t.Month()], t.Year())
+}
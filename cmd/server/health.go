@@ -0,0 +1,120 @@
+package main
+
+import (
+	"net/http"
+	"time"
+	"vidit/internal/database"
+	"vidit/internal/fetcher"
+	"vidit/internal/models"
+
+	"github.com/labstack/echo/v4"
+)
+
+// FeedHealth is a row of the health dashboard
+type FeedHealth struct {
+	Feed         models.Feed
+	Attempts     int64
+	Successes    int64
+	AvgLatencyMs float64
+	RecentLogs   []models.FeedFetchLog
+}
+
+// SuccessRate is the percentage of successful attempts in the last 24 hours
+func (h FeedHealth) SuccessRate() int64 {
+	if h.Attempts == 0 {
+		return 0
+	}
+	return h.Successes * 100 / h.Attempts
+}
+
+func handleAdminHealth(c echo.Context) error {
+	var totalFeeds, disabledFeeds int64
+	database.DB.Model(&models.Feed{}).Count(&totalFeeds)
+	database.DB.Model(&models.Feed{}).Where("disabled_at IS NOT NULL").Count(&disabledFeeds)
+
+	// Failing (or auto-disabled) sources, worst first
+	var feeds []models.Feed
+	err := database.DB.
+		Where("consecutive_failures > 0 OR disabled_at IS NOT NULL").
+		Order("disabled_at IS NULL, consecutive_failures DESC, name ASC").
+		Find(&feeds).Error
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "Error loading feeds")
+	}
+
+	feedIDs := make([]uint, len(feeds))
+	for i, f := range feeds {
+		feedIDs[i] = f.ID
+	}
+
+	since := time.Now().Add(-24 * time.Hour)
+
+	type logStats struct {
+		FeedID       uint
+		Attempts     int64
+		Successes    int64
+		AvgLatencyMs float64
+	}
+	var stats []logStats
+	var logs []models.FeedFetchLog
+
+	if len(feedIDs) > 0 {
+		database.DB.Model(&models.FeedFetchLog{}).
+			Select("feed_id, COUNT(*) AS attempts, SUM(CASE WHEN success THEN 1 ELSE 0 END) AS successes, AVG(latency_ms) AS avg_latency_ms").
+			Where("feed_id IN ? AND created_at > ?", feedIDs, since).
+			Group("feed_id").
+			Scan(&stats)
+
+		database.DB.
+			Where("feed_id IN ? AND created_at > ?", feedIDs, since).
+			Order("created_at DESC").
+			Find(&logs)
+	}
+
+	statsByFeed := make(map[uint]logStats, len(stats))
+	for _, s := range stats {
+		statsByFeed[s.FeedID] = s
+	}
+
+	logsByFeed := make(map[uint][]models.FeedFetchLog)
+	for _, l := range logs {
+		if len(logsByFeed[l.FeedID]) < 5 {
+			logsByFeed[l.FeedID] = append(logsByFeed[l.FeedID], l)
+		}
+	}
+
+	rows := make([]FeedHealth, len(feeds))
+	for i, f := range feeds {
+		st := statsByFeed[f.ID]
+		rows[i] = FeedHealth{
+			Feed:         f,
+			Attempts:     st.Attempts,
+			Successes:    st.Successes,
+			AvgLatencyMs: st.AvgLatencyMs,
+			RecentLogs:   logsByFeed[f.ID],
+		}
+	}
+
+	return c.Render(http.StatusOK, "health.html", map[string]interface{}{
+		"Feeds":    rows,
+		"Total":    totalFeeds,
+		"Failing":  int64(len(feeds)) - disabledFeeds,
+		"Disabled": disabledFeeds,
+		"Healthy":  totalFeeds - int64(len(feeds)),
+	})
+}
+
+func handleAdminEnableFeed(c echo.Context) error {
+	feed, ok, err := findAdminFeed(c)
+	if !ok {
+		return err
+	}
+
+	if err := fetcher.EnableFeed(database.DB, feed); err != nil {
+		return apiError(c, http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{
+		"status": "Feed enabled",
+	})
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"html/template"
 	"io"
@@ -10,15 +11,18 @@ import (
 	"time"
 	"vidit/internal/database"
 	"vidit/internal/fetcher"
-	"vidit/internal/mastodon"
 	"vidit/internal/models"
 
-	gomastodon "github.com/mattn/go-mastodon"
-
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 )
 
+// The front page shows the best stories seen within frontPageWindow
+const (
+	frontPageWindow = 48 * time.Hour
+	frontPageSize   = 100
+)
+
 // TemplateRenderer is a custom html/template renderer for Echo
 type TemplateRenderer struct {
 	templates *template.Template
@@ -48,6 +52,10 @@ func main() {
 		log.Fatalf("Failed to migrate database: %v", err)
 	}
 
+	if _, err := fetcher.LoadRankingConfig(database.DB); err != nil {
+		log.Printf("⚠️  Using default ranking configuration: %v\n", err)
+	}
+
 	e := echo.New()
 	e.HideBanner = true
 
@@ -72,6 +80,11 @@ func main() {
 		"formatScore": func(score float64) string {
 			return fmt.Sprintf("%.2f", score)
 		},
+		// now is the page's reference time, the one its scores were computed at
+		"scoreBreakdown": func(article models.Article, now time.Time) string {
+			rs := &fetcher.RankingService{Now: now}
+			return rs.Breakdown(article).String()
+		},
 		"safeHTML": func(s string) template.HTML {
 			return template.HTML(s)
 		},
@@ -87,80 +100,80 @@ func main() {
 	e.Static("/css", "public/css")
 
 	e.GET("/", handleHome)
+	e.GET("/search", handleSearch)
 	e.POST("/fetch", handleFetch)
 
-	// Start background fetcher
+	registerArchive(e)
+	registerSyndication(e)
+	registerSections(e)
+	registerAPI(e)
+	registerAdmin(e)
+
+	// Start background fetcher: each feed is polled on its own adaptive schedule
 	go func() {
-		// Initial fetch after server starts (give it a moment to settle)
+		// Give the server a moment to settle
 		time.Sleep(5 * time.Second)
-		log.Println("🔄 Starting background feed fetcher...")
+		log.Println("🔄 Starting background feed scheduler...")
 
-		service := fetcher.NewService()
-		if err := service.FetchAllFeeds(database.DB); err != nil {
-			log.Printf("❌ Initial fetch failed: %v\n", err)
-		}
+		scheduler := fetcher.NewScheduler(database.DB, fetcher.NewService())
+		scheduler.AfterFetch = publishStories
+		scheduler.Run(context.Background())
+	}()
 
-		// Periodic fetch
-		ticker := time.NewTicker(15 * time.Minute)
-		defer ticker.Stop()
+	// Pick up ranking configuration changes without a restart
+	go fetcher.WatchRankingConfig(context.Background(), database.DB)
 
-		for range ticker.C {
-			log.Println("🔄 Starting periodic feed fetch...")
-			if err := service.FetchAllFeeds(database.DB); err != nil {
-				log.Printf("❌ Periocic fetch failed: %v\n", err)
-			}
-		}
-	}()
+	// Keep stored scores time-decayed between fetches
+	go fetcher.NewRescorer(database.DB).Run(context.Background())
+
+	// Keep the Mastodon posts of the top stories warm
+	go trendCache.Run(context.Background(), topStoryKeywords)
 
 	port := getEnv("PORT", "3000")
 	log.Printf("🚀 Vidit server starting on http://localhost:%s\n", port)
 	e.Logger.Fatal(e.Start(":" + port))
 }
 
-func handleHome(c echo.Context) error {
-	var articles []models.Article
+// frontPage loads the best recent stories
+func frontPage() ([]models.Story, error) {
+	var stories []models.Story
 
-	result := database.DB.
+	// One card per story; the lead article's feed must still be active
+	err := database.DB.
+		Joins("JOIN articles ON articles.id = stories.lead_article_id AND articles.deleted_at IS NULL").
 		Joins("JOIN feeds ON feeds.id = articles.feed_id AND feeds.deleted_at IS NULL").
-		Preload("Feed").
-		Where("articles.published_at > ?", time.Now().Add(-48*time.Hour)). // Only last 48 hours
-		Order("articles.score DESC, articles.published_at DESC").
-		Limit(100).
-		Find(&articles)
-
-	if result.Error != nil {
-		return c.String(http.StatusInternalServerError, "Error loading articles")
-	}
+		Preload("LeadArticle.Feed").
+		Preload("Articles.Feed").
+		Where("stories.last_seen_at > ?", time.Now().Add(-frontPageWindow)).
+		Order("stories.score DESC, stories.last_seen_at DESC").
+		Limit(frontPageSize).
+		Find(&stories).Error
+	return stories, err
+}
 
-	// Fetch Mastodon Trends for the top article
-	var mastodonTrends []*gomastodon.Status
-	if len(articles) > 0 {
-		ms := mastodon.NewService()
-		// Improved keyword extraction: Try top article
-		kw := mastodon.ExtractKeywords(articles[0].Title)
-		log.Printf("🐘 Fetching Mastodon trends for keyword: %s", kw)
-
-		trends, err := ms.GetTrends(kw)
-		if err != nil {
-			log.Printf("⚠️ Mastodon fetch failed: %v", err)
-		} else {
-			mastodonTrends = trends
-		}
+func handleHome(c echo.Context) error {
+	stories, err := frontPage()
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "Error loading stories")
 	}
 
+	// Mastodon Trends for the top stories, from the cache (refreshed in the background)
+	mastodonTrends, mastodonTags := cachedTrends(stories)
+
 	return c.Render(http.StatusOK, "index.html", map[string]interface{}{
-		"Articles":       articles,
-		"Count":          len(articles),
+		"Stories":        stories,
+		"Count":          len(stories),
+		"Now":            time.Now(),
 		"MastodonTrends": mastodonTrends,
+		"MastodonTags":   mastodonTags,
+		"Sections":       sectionNav(),
 	})
 }
 
-// function getTrendingTopics deleted
-
 func handleFetch(c echo.Context) error {
 	service := fetcher.NewService()
 
-	if err := service.FetchAllFeeds(database.DB); err != nil {
+	if err := service.FetchAllFeeds(c.Request().Context(), database.DB); err != nil {
 		return c.JSON(http.StatusInternalServerError, map[string]string{
 			"error": err.Error(),
 		})
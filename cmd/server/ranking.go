@@ -0,0 +1,47 @@
+package main
+
+import (
+	"net/http"
+	"vidit/internal/database"
+	"vidit/internal/fetcher"
+
+	"github.com/labstack/echo/v4"
+)
+
+func handleAdminRanking(c echo.Context) error {
+	return c.JSON(http.StatusOK, fetcher.CurrentRankingConfig())
+}
+
+// handleAdminUpdateRanking accepts a full or partial configuration;
+// missing fields keep their current value
+func handleAdminUpdateRanking(c echo.Context) error {
+	current := fetcher.CurrentRankingConfig()
+
+	// Boost maps are replaced as a whole, not merged into the ones in use
+	cfg := current
+	cfg.CountryBoosts, cfg.CategoryBoosts = nil, nil
+	if err := c.Bind(&cfg); err != nil {
+		return apiError(c, http.StatusBadRequest, "invalid payload")
+	}
+	if cfg.CountryBoosts == nil {
+		cfg.CountryBoosts = current.CountryBoosts
+	}
+	if cfg.CategoryBoosts == nil {
+		cfg.CategoryBoosts = current.CategoryBoosts
+	}
+
+	cfg, err := fetcher.SaveRankingConfig(database.DB, cfg)
+	if err != nil {
+		return apiError(c, http.StatusBadRequest, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, cfg)
+}
+
+func handleAdminReloadRanking(c echo.Context) error {
+	cfg, err := fetcher.LoadRankingConfig(database.DB)
+	if err != nil {
+		return apiError(c, http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, cfg)
+}
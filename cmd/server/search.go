@@ -0,0 +1,178 @@
+package main
+
+import (
+	"html"
+	"html/template"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"vidit/internal/database"
+	"vidit/internal/fetcher"
+	"vidit/internal/models"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+const searchPerPage = 60
+
+// Markers placed by ts_headline around matches; the text is escaped first
+// and only then are they turned into <mark> tags
+const (
+	markStart = "⟪"
+	markStop  = "⟫"
+)
+
+var headlineOptions = "StartSel=" + markStart + ", StopSel=" + markStop
+
+// Highlight holds the matched fragments of an article, ready to render
+type Highlight struct {
+	Title   template.HTML
+	Summary template.HTML
+}
+
+// searchResult is an article plus the columns computed by the search query
+type searchResult struct {
+	models.Article
+	Rank             float64
+	TitleHighlight   string
+	SummaryHighlight string
+}
+
+// handleSearch runs a full-text search over the whole archive and renders
+// the results in the same mosaic as the front page.
+// Parameters: q, from and to (YYYY-MM-DD), feed (repeatable) and page.
+func handleSearch(c echo.Context) error {
+	q := strings.TrimSpace(c.QueryParam("q"))
+	now := time.Now()
+
+	var feeds []models.Feed
+	database.DB.Order("name ASC").Find(&feeds)
+
+	data := map[string]interface{}{
+		"Query":   q,
+		"Search":  true,
+		"Feeds":   feeds,
+		"From":    c.QueryParam("from"),
+		"To":      c.QueryParam("to"),
+		"FeedIDs": map[uint]bool{},
+		"Stories": []models.Story{},
+		"Count":   0,
+		"Total":   int64(0),
+		"Now":     now,
+		"Page":    1,
+		"NextURL": "",
+	}
+
+	if q == "" {
+		return c.Render(http.StatusOK, "index.html", data)
+	}
+
+	tsQuery := "websearch_to_tsquery('spanish', ?)"
+	query := database.DB.Model(&models.Article{}).
+		Joins("JOIN feeds ON feeds.id = articles.feed_id AND feeds.deleted_at IS NULL").
+		Where("articles.search_vector @@ "+tsQuery, q)
+
+	if raw := c.QueryParam("from"); raw != "" {
+		from, err := time.ParseInLocation("2006-01-02", raw, time.Local)
+		if err != nil {
+			return c.String(http.StatusBadRequest, "Fecha 'desde' inválida (usa AAAA-MM-DD)")
+		}
+		query = query.Where("articles.published_at >= ?", from)
+	}
+	if raw := c.QueryParam("to"); raw != "" {
+		to, err := time.ParseInLocation("2006-01-02", raw, time.Local)
+		if err != nil {
+			return c.String(http.StatusBadRequest, "Fecha 'hasta' inválida (usa AAAA-MM-DD)")
+		}
+		query = query.Where("articles.published_at < ?", to.AddDate(0, 0, 1)) // Inclusive
+	}
+
+	var feedIDs []uint
+	selected := map[uint]bool{}
+	for _, raw := range c.QueryParams()["feed"] {
+		if id, err := strconv.ParseUint(raw, 10, 64); err == nil {
+			feedIDs = append(feedIDs, uint(id))
+			selected[uint(id)] = true
+		}
+	}
+	if len(feedIDs) > 0 {
+		query = query.Where("articles.feed_id IN ?", feedIDs)
+	}
+	data["FeedIDs"] = selected
+
+	page, _ := strconv.Atoi(c.QueryParam("page"))
+	if page < 1 {
+		page = 1
+	}
+
+	// New session so Count doesn't leak into the page query
+	query = query.Session(&gorm.Session{})
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return c.String(http.StatusInternalServerError, "Error searching articles")
+	}
+	data["Total"] = total
+
+	var results []searchResult
+	err := query.
+		Select("articles.*, "+
+			"ts_rank_cd(articles.search_vector, "+tsQuery+") AS rank, "+
+			"ts_headline('spanish', articles.title, "+tsQuery+", ?) AS title_highlight, "+
+			"ts_headline('spanish', coalesce(articles.summary, ''), "+tsQuery+", ?) AS summary_highlight",
+			q, q, headlineOptions+", HighlightAll=true", q, headlineOptions+", MaxWords=40, MinWords=20").
+		Order("rank DESC, articles.published_at DESC").
+		Offset((page - 1) * searchPerPage).
+		Limit(searchPerPage + 1). // One extra to know if there is a next page
+		Find(&results).Error
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "Error searching articles")
+	}
+
+	if len(results) > searchPerPage {
+		results = results[:searchPerPage]
+		next := c.QueryParams()
+		next.Set("page", strconv.Itoa(page+1))
+		data["NextURL"] = "/search?" + next.Encode()
+	}
+
+	feedsByID := make(map[uint]models.Feed, len(feeds))
+	for _, f := range feeds {
+		feedsByID[f.ID] = f
+	}
+
+	// One card per article, reusing the story card of the mosaic. Stored scores date
+	// from each article's last rescoring, so they are recomputed for the page's time.
+	rs := &fetcher.RankingService{Now: now}
+	stories := make([]models.Story, len(results))
+	highlights := make(map[uint]Highlight, len(results))
+	for i, r := range results {
+		r.Feed = feedsByID[r.FeedID]
+		stories[i] = models.Story{
+			Title:       r.Title,
+			Score:       rs.Gravity(r.Article, r.ClusterCount),
+			LeadArticle: r.Article,
+			Articles:    []models.Article{r.Article},
+		}
+		highlights[r.ID] = Highlight{
+			Title:   markHighlights(r.TitleHighlight),
+			Summary: markHighlights(r.SummaryHighlight),
+		}
+	}
+
+	data["Stories"] = stories
+	data["Highlights"] = highlights
+	data["Count"] = len(stories)
+	data["Page"] = page
+	return c.Render(http.StatusOK, "index.html", data)
+}
+
+// markHighlights escapes a ts_headline fragment and turns its markers into <mark> tags
+func markHighlights(fragment string) template.HTML {
+	escaped := html.EscapeString(fragment)
+	escaped = strings.ReplaceAll(escaped, markStart, "<mark>")
+	escaped = strings.ReplaceAll(escaped, markStop, "</mark>")
+	return template.HTML(escaped)
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+	"vidit/internal/database"
+	"vidit/internal/fetcher"
+	"vidit/internal/models"
+
+	"github.com/labstack/echo/v4"
+)
+
+// categoryLabels are the display names of the known feed categories
+var categoryLabels = map[string]string{
+	"general":       "General",
+	"cybersecurity": "Ciberseguridad",
+	"international": "Internacional",
+	"latam":         "Latinoamérica",
+	"usa":           "EE.UU.",
+	"china":         "China",
+}
+
+// Section is a link of the category/country navigation
+type Section struct {
+	Label string
+	Path  string
+}
+
+// registerSections mounts the category and country landing pages
+func registerSections(e *echo.Echo) {
+	e.GET("/c/:category", handleCategory)
+	e.GET("/country/:code", handleCountry)
+}
+
+// Conditions selecting the feeds of a section; values are lowercase categories
+// and uppercase country codes
+const (
+	categoryCondition = "LOWER(feeds.category) = ?"
+	countryCondition  = "UPPER(feeds.country) = ?"
+)
+
+func handleCategory(c echo.Context) error {
+	category := strings.ToLower(c.Param("category"))
+	return renderSection(c, categoryLabel(category), "/c/"+category, "/feed/"+category+".rss",
+		categoryCondition, category)
+}
+
+func handleCountry(c echo.Context) error {
+	code := strings.ToUpper(c.Param("code"))
+	return renderSection(c, code, "/country/"+code, "/feed/country/"+code+".rss",
+		countryCondition, code)
+}
+
+func renderSection(c echo.Context, label, path, feedURL, condition, value string) error {
+	now := time.Now()
+	stories, found, err := sectionStories(condition, value, now)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "Error loading articles")
+	}
+	if !found {
+		return c.String(http.StatusNotFound, "Sección desconocida")
+	}
+
+	return c.Render(http.StatusOK, "index.html", map[string]interface{}{
+		"Stories":     stories,
+		"Count":       len(stories),
+		"Now":         now,
+		"Section":     label,
+		"SectionPath": path,
+		"SectionFeed": feedURL,
+		"Sections":    sectionNav(),
+	})
+}
+
+// sectionStories ranks only the articles of the feeds matching the condition: cluster
+// counts and stories are computed within the slice, so its own big stories surface
+// even if they are small next to the whole front page. The section page and its
+// syndication feeds both use it. found is false when no feed matches.
+func sectionStories(condition, value string, now time.Time) (stories []models.Story, found bool, err error) {
+	var articles []models.Article
+	err = database.DB.
+		Joins("JOIN feeds ON feeds.id = articles.feed_id AND feeds.deleted_at IS NULL").
+		Preload("Feed").
+		Where(condition, value).
+		Where("articles.published_at > ? AND articles.published_at <= ?", now.Add(-frontPageWindow), now).
+		Find(&articles).Error
+	if err != nil {
+		return nil, false, err
+	}
+
+	if len(articles) == 0 {
+		var feeds int64
+		if err := database.DB.Model(&models.Feed{}).Where(condition, value).Count(&feeds).Error; err != nil {
+			return nil, false, err
+		}
+		if feeds == 0 {
+			return nil, false, nil
+		}
+	}
+
+	rs := &fetcher.RankingService{Now: now}
+	stories = rs.SnapshotStories(articles)
+	if len(stories) > frontPageSize {
+		stories = stories[:frontPageSize]
+	}
+	return stories, true, nil
+}
+
+// sectionNavTTL is how long the section links are reused; feed changes show up
+// in the navigation within it
+const sectionNavTTL = time.Minute
+
+var (
+	sectionNavMu      sync.Mutex
+	sectionNavCache   []Section
+	sectionNavBuiltAt time.Time
+)
+
+// sectionNav lists the categories and countries that have active feeds,
+// reloading them at most once per sectionNavTTL
+func sectionNav() []Section {
+	sectionNavMu.Lock()
+	defer sectionNavMu.Unlock()
+	if time.Since(sectionNavBuiltAt) < sectionNavTTL {
+		return sectionNavCache
+	}
+	sectionNavCache, sectionNavBuiltAt = loadSectionNav(), time.Now()
+	return sectionNavCache
+}
+
+func loadSectionNav() []Section {
+	var categories, countries []string
+	database.DB.Raw("SELECT DISTINCT LOWER(category) FROM feeds WHERE deleted_at IS NULL ORDER BY 1").Scan(&categories)
+	database.DB.Raw("SELECT DISTINCT UPPER(country) FROM feeds WHERE deleted_at IS NULL ORDER BY 1").Scan(&countries)
+
+	var sections []Section
+	for _, category := range categories {
+		if category != "" {
+			sections = append(sections, Section{Label: categoryLabel(category), Path: "/c/" + category})
+		}
+	}
+	for _, country := range countries {
+		// INT is the catch-all, already covered by the categories
+		if country != "" && country != "INT" {
+			sections = append(sections, Section{Label: country, Path: "/country/" + country})
+		}
+	}
+	return sections
+}
+
+func categoryLabel(category string) string {
+	if label, ok := categoryLabels[category]; ok {
+		return label
+	}
+	return category
+}
@@ -0,0 +1,442 @@
+package main
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+	"vidit/internal/database"
+	"vidit/internal/models"
+
+	"github.com/labstack/echo/v4"
+)
+
+// viditNS qualifies the extra elements (score, number of sources) of the RSS and Atom output
+const viditNS = "urn:vidit:feed:1.0"
+
+// registerSyndication publishes the front page as RSS 2.0, Atom and JSON Feed 1.1,
+// and the category and country pages, ranked the same way as on the site:
+// /feed.rss, /feed/latam.atom, /feed/country/CL.json...
+func registerSyndication(e *echo.Echo) {
+	for _, format := range []string{"rss", "atom", "json"} {
+		format := format
+		e.GET("/feed."+format, func(c echo.Context) error {
+			return renderSyndication(c, format, "", "")
+		})
+	}
+	e.GET("/feed/:file", handleCategorySyndication)
+	e.GET("/feed/country/:file", handleCountrySyndication)
+}
+
+func handleCategorySyndication(c echo.Context) error {
+	category, format, ok := splitFeedFile(c.Param("file"))
+	if !ok {
+		return c.String(http.StatusNotFound, "Formato desconocido (usa .rss, .atom o .json)")
+	}
+	return renderSyndication(c, format, category, "")
+}
+
+func handleCountrySyndication(c echo.Context) error {
+	country, format, ok := splitFeedFile(c.Param("file"))
+	if !ok {
+		return c.String(http.StatusNotFound, "Formato desconocido (usa .rss, .atom o .json)")
+	}
+	return renderSyndication(c, format, "", country)
+}
+
+// splitFeedFile splits "latam.rss" into "latam" and "rss"
+func splitFeedFile(file string) (name, format string, ok bool) {
+	ext := path.Ext(file)
+	name = strings.TrimSuffix(file, ext)
+	format = strings.TrimPrefix(ext, ".")
+	switch format {
+	case "rss", "atom", "json":
+		return name, format, name != ""
+	}
+	return "", "", false
+}
+
+// syndication is what every output format is built from
+type syndication struct {
+	Title   string
+	HomeURL string
+	SelfURL string
+	Updated time.Time
+	Stories []models.Story
+}
+
+func renderSyndication(c echo.Context, format, category, country string) error {
+	var stories []models.Story
+	var err error
+	found := true
+	switch {
+	case category != "":
+		stories, found, err = sectionStories(categoryCondition, strings.ToLower(category), time.Now())
+	case country != "":
+		stories, found, err = sectionStories(countryCondition, strings.ToUpper(country), time.Now())
+	default:
+		stories, err = frontPage()
+	}
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "Error loading stories")
+	}
+	// Unknown slices are a 404 rather than an empty feed that readers would keep polling
+	if !found {
+		return c.String(http.StatusNotFound, "No hay fuentes para ese filtro")
+	}
+	if category != "" || country != "" {
+		storedStoryIDs(stories)
+	}
+
+	base := siteURL(c)
+	s := syndication{
+		Title:   "Vidit",
+		HomeURL: base + "/",
+		SelfURL: base + c.Request().URL.Path,
+		Updated: time.Now(),
+		Stories: stories,
+	}
+	switch {
+	case category != "":
+		s.Title += " · " + strings.ToLower(category)
+		s.HomeURL = base + "/c/" + strings.ToLower(category)
+	case country != "":
+		s.Title += " · " + strings.ToUpper(country)
+		s.HomeURL = base + "/country/" + strings.ToUpper(country)
+	}
+	if len(stories) > 0 {
+		s.Updated = stories[0].UpdatedAt
+		for _, story := range stories {
+			if story.UpdatedAt.After(s.Updated) {
+				s.Updated = story.UpdatedAt
+			}
+		}
+	}
+
+	c.Response().Header().Set("Cache-Control", "public, max-age=300")
+
+	switch format {
+	case "atom":
+		return renderXML(c, "application/atom+xml; charset=utf-8", s.atom())
+	case "json":
+		c.Response().Header().Set(echo.HeaderContentType, "application/feed+json; charset=utf-8")
+		c.Response().WriteHeader(http.StatusOK)
+		enc := json.NewEncoder(c.Response())
+		enc.SetIndent("", "  ")
+		return enc.Encode(s.jsonFeed())
+	default:
+		return renderXML(c, "application/rss+xml; charset=utf-8", s.rss())
+	}
+}
+
+func renderXML(c echo.Context, contentType string, doc interface{}) error {
+	c.Response().Header().Set(echo.HeaderContentType, contentType)
+	c.Response().WriteHeader(http.StatusOK)
+	if _, err := c.Response().Write([]byte(xml.Header)); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(c.Response())
+	enc.Indent("", "  ")
+	return enc.Encode(doc)
+}
+
+// siteURL is SITE_URL, or the scheme and host the request came in with
+func siteURL(c echo.Context) string {
+	if site := getEnv("SITE_URL", ""); site != "" {
+		return strings.TrimRight(site, "/")
+	}
+	return c.Scheme() + "://" + c.Request().Host
+}
+
+// storedStoryIDs gives the in-memory stories of a section the ID and update time
+// of the stored story of their lead, so entries keep their id between fetches
+func storedStoryIDs(stories []models.Story) {
+	leads := make([]uint, len(stories))
+	for i, story := range stories {
+		leads[i] = story.LeadArticleID
+	}
+
+	var links []models.StoryArticle
+	database.DB.Where("article_id IN ?", leads).Find(&links)
+	byArticle := make(map[uint]uint, len(links))
+	for _, l := range links {
+		byArticle[l.ArticleID] = l.StoryID
+	}
+
+	for i := range stories {
+		stories[i].ID = byArticle[stories[i].LeadArticleID]
+		stories[i].UpdatedAt = stories[i].LastSeenAt
+	}
+}
+
+// storyID is the entry id: the stored story's, or the lead article's for stories
+// not stored yet
+func storyID(story models.Story) string {
+	if story.ID == 0 {
+		return fmt.Sprintf("urn:vidit:article:%d", story.LeadArticleID)
+	}
+	return fmt.Sprintf("urn:vidit:story:%d", story.ID)
+}
+
+// storySources is the number of outlets that covered the story
+func storySources(story models.Story) int {
+	return len(story.OtherSources()) + 1
+}
+
+// storyText is the lead's summary followed by its source, score and the other sources
+func storyText(story models.Story) string {
+	lead := story.LeadArticle
+	var b strings.Builder
+	if lead.Summary != "" {
+		b.WriteString(lead.Summary)
+		b.WriteString("\n\n")
+	}
+	fmt.Fprintf(&b, "Fuente: %s · Gravity %.2f", lead.Feed.Name, story.Score)
+
+	var others []string
+	for _, a := range story.OtherSources() {
+		others = append(others, a.Feed.Name)
+	}
+	if len(others) > 0 {
+		b.WriteString(" · También en: " + strings.Join(others, ", "))
+	}
+	return b.String()
+}
+
+// RSS 2.0
+
+type rssDoc struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	AtomNS  string     `xml:"xmlns:atom,attr"`
+	ViditNS string     `xml:"xmlns:vidit,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	Language      string    `xml:"language"`
+	LastBuildDate string    `xml:"lastBuildDate"`
+	Self          atomLink  `xml:"atom:link"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string    `xml:"title"`
+	Link        string    `xml:"link"`
+	GUID        rssGUID   `xml:"guid"`
+	Description string    `xml:"description"`
+	PubDate     string    `xml:"pubDate"`
+	Categories  []string  `xml:"category"`
+	Source      rssSource `xml:"source"`
+	Score       string    `xml:"vidit:score"`
+	Sources     int       `xml:"vidit:sources"`
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+type rssSource struct {
+	URL  string `xml:"url,attr"`
+	Name string `xml:",chardata"`
+}
+
+func (s syndication) rss() rssDoc {
+	doc := rssDoc{
+		Version: "2.0",
+		AtomNS:  "http://www.w3.org/2005/Atom",
+		ViditNS: viditNS,
+		Channel: rssChannel{
+			Title:         s.Title,
+			Link:          s.HomeURL,
+			Description:   "Las noticias más relevantes del momento, ordenadas por Vidit",
+			Language:      "es",
+			LastBuildDate: s.Updated.Format(time.RFC1123Z),
+			Self:          atomLink{Href: s.SelfURL, Rel: "self", Type: "application/rss+xml"},
+		},
+	}
+
+	for _, story := range s.Stories {
+		lead := story.LeadArticle
+		item := rssItem{
+			Title:       lead.Title,
+			Link:        lead.URL,
+			GUID:        rssGUID{Value: storyID(story)},
+			Description: storyText(story),
+			PubDate:     lead.PublishedAt.Format(time.RFC1123Z),
+			Categories:  lead.Categories,
+			Source:      rssSource{URL: lead.Feed.URL, Name: lead.Feed.Name},
+			Score:       fmt.Sprintf("%.4f", story.Score),
+			Sources:     storySources(story),
+		}
+		doc.Channel.Items = append(doc.Channel.Items, item)
+	}
+	return doc
+}
+
+// Atom
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	ViditNS string      `xml:"xmlns:vidit,attr"`
+	Lang    string      `xml:"xml:lang,attr"`
+	Title   string      `xml:"title"`
+	ID      string      `xml:"id"`
+	Updated string      `xml:"updated"`
+	Links   []atomLink  `xml:"link"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type atomEntry struct {
+	Title      string         `xml:"title"`
+	ID         string         `xml:"id"`
+	Link       atomLink       `xml:"link"`
+	Published  string         `xml:"published"`
+	Updated    string         `xml:"updated"`
+	Author     atomPerson     `xml:"author"`
+	Summary    string         `xml:"summary"`
+	Categories []atomCategory `xml:"category"`
+	Source     atomSource     `xml:"source"`
+	Score      string         `xml:"vidit:score"`
+	Sources    int            `xml:"vidit:sources"`
+}
+
+type atomPerson struct {
+	Name string `xml:"name"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+type atomSource struct {
+	Title string   `xml:"title"`
+	Link  atomLink `xml:"link"`
+}
+
+func (s syndication) atom() atomFeed {
+	feed := atomFeed{
+		ViditNS: viditNS,
+		Lang:    "es",
+		Title:   s.Title,
+		ID:      s.SelfURL,
+		Updated: s.Updated.Format(time.RFC3339),
+		Links: []atomLink{
+			{Href: s.HomeURL, Rel: "alternate", Type: "text/html"},
+			{Href: s.SelfURL, Rel: "self", Type: "application/atom+xml"},
+		},
+	}
+
+	for _, story := range s.Stories {
+		lead := story.LeadArticle
+		entry := atomEntry{
+			Title:     lead.Title,
+			ID:        storyID(story),
+			Link:      atomLink{Href: lead.URL, Rel: "alternate"},
+			Published: lead.PublishedAt.Format(time.RFC3339),
+			Updated:   story.UpdatedAt.Format(time.RFC3339),
+			Author:    atomPerson{Name: lead.Feed.Name},
+			Summary:   storyText(story),
+			Source:    atomSource{Title: lead.Feed.Name, Link: atomLink{Href: lead.Feed.URL}},
+			Score:     fmt.Sprintf("%.4f", story.Score),
+			Sources:   storySources(story),
+		}
+		if lead.Author != "" {
+			entry.Author.Name = lead.Author + " (" + lead.Feed.Name + ")"
+		}
+		for _, category := range lead.Categories {
+			entry.Categories = append(entry.Categories, atomCategory{Term: category})
+		}
+		feed.Entries = append(feed.Entries, entry)
+	}
+	return feed
+}
+
+// JSON Feed 1.1 (https://jsonfeed.org/version/1.1)
+
+type jsonFeed struct {
+	Version     string         `json:"version"`
+	Title       string         `json:"title"`
+	HomePageURL string         `json:"home_page_url"`
+	FeedURL     string         `json:"feed_url"`
+	Description string         `json:"description"`
+	Language    string         `json:"language"`
+	Items       []jsonFeedItem `json:"items"`
+}
+
+type jsonFeedItem struct {
+	ID            string           `json:"id"`
+	URL           string           `json:"url"`
+	Title         string           `json:"title"`
+	ContentText   string           `json:"content_text"`
+	Summary       string           `json:"summary,omitempty"`
+	Image         string           `json:"image,omitempty"`
+	DatePublished string           `json:"date_published"`
+	DateModified  string           `json:"date_modified"`
+	Authors       []jsonFeedAuthor `json:"authors,omitempty"`
+	Tags          []string         `json:"tags,omitempty"`
+	Vidit         jsonFeedVidit    `json:"_vidit"`
+}
+
+type jsonFeedAuthor struct {
+	Name string `json:"name"`
+}
+
+// jsonFeedVidit is the extension object with the ranking details
+type jsonFeedVidit struct {
+	Source    string  `json:"source"`
+	SourceURL string  `json:"source_url"`
+	Score     float64 `json:"score"`
+	Sources   int     `json:"sources"`
+}
+
+func (s syndication) jsonFeed() jsonFeed {
+	feed := jsonFeed{
+		Version:     "https://jsonfeed.org/version/1.1",
+		Title:       s.Title,
+		HomePageURL: s.HomeURL,
+		FeedURL:     s.SelfURL,
+		Description: "Las noticias más relevantes del momento, ordenadas por Vidit",
+		Language:    "es",
+		Items:       []jsonFeedItem{},
+	}
+
+	for _, story := range s.Stories {
+		lead := story.LeadArticle
+		item := jsonFeedItem{
+			ID:            storyID(story),
+			URL:           lead.URL,
+			Title:         lead.Title,
+			ContentText:   storyText(story),
+			Summary:       lead.Summary,
+			Image:         lead.ImageURL,
+			DatePublished: lead.PublishedAt.Format(time.RFC3339),
+			DateModified:  story.UpdatedAt.Format(time.RFC3339),
+			Tags:          lead.Categories,
+			Vidit: jsonFeedVidit{
+				Source:    lead.Feed.Name,
+				SourceURL: lead.Feed.URL,
+				Score:     story.Score,
+				Sources:   storySources(story),
+			},
+		}
+		if lead.Author != "" {
+			item.Authors = []jsonFeedAuthor{{Name: lead.Author}}
+		}
+		feed.Items = append(feed.Items, item)
+	}
+	return feed
+}
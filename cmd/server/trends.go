@@ -0,0 +1,134 @@
+package main
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+	"vidit/internal/database"
+	"vidit/internal/keyphrase"
+	"vidit/internal/mastodon"
+	"vidit/internal/models"
+
+	gomastodon "github.com/mattn/go-mastodon"
+)
+
+// Titles the keyword IDF is computed against, and how often they are reloaded
+const (
+	corpusWindow  = 30 * 24 * time.Hour
+	corpusMaxDocs = 50000
+	corpusTTL     = time.Hour
+	maxTrendPosts = 10
+)
+
+var (
+	mastodonConfig = mastodon.ConfigFromEnv()
+
+	// trendCache serves the Mastodon posts shown on the front page
+	trendCache = mastodon.NewTrendCache(mastodon.NewServiceWithConfig(mastodonConfig))
+
+	// publisher posts new big stories from the bot account, if enabled
+	publisher *mastodon.Publisher
+
+	// archiveCorpus is only built by the background refresher, so pages never wait for it
+	corpusMu      sync.RWMutex
+	archiveCorpus *keyphrase.Corpus
+	corpusBuiltAt time.Time
+)
+
+// publishStories runs after each fetch round of the scheduler
+func publishStories(ctx context.Context) {
+	if publisher == nil {
+		publisher = mastodon.NewPublisher(database.DB, mastodon.NewServiceWithConfig(mastodonConfig), mastodon.PublisherConfigFromEnv())
+	}
+	if _, err := publisher.Publish(ctx); err != nil {
+		log.Printf("❌ Mastodon publishing failed: %v", err)
+	}
+}
+
+// currentCorpus returns the archive corpus, or nil before it is first built
+func currentCorpus() *keyphrase.Corpus {
+	corpusMu.RLock()
+	defer corpusMu.RUnlock()
+	return archiveCorpus
+}
+
+// refreshCorpus reloads the recent titles of the archive once corpusTTL has passed
+func refreshCorpus() {
+	corpusMu.RLock()
+	fresh := time.Since(corpusBuiltAt) < corpusTTL
+	corpusMu.RUnlock()
+	if fresh {
+		return
+	}
+
+	var titles []string
+	err := database.DB.Model(&models.Article{}).
+		Where("published_at > ?", time.Now().Add(-corpusWindow)).
+		Order("published_at DESC").
+		Limit(corpusMaxDocs).
+		Pluck("title", &titles).Error
+	if err != nil {
+		log.Printf("⚠️ Could not load titles for keyword IDF: %v", err)
+		return
+	}
+
+	corpus := keyphrase.NewCorpus(titles)
+	corpusMu.Lock()
+	archiveCorpus, corpusBuiltAt = corpus, time.Now()
+	corpusMu.Unlock()
+	log.Printf("🔤 Keyword corpus rebuilt from %d titles", corpus.Docs())
+}
+
+// storyKeyword is the hashtag (without "#") describing the whole cluster of a story
+func storyKeyword(story models.Story) string {
+	titles := []string{story.LeadArticle.Title}
+	for _, a := range story.Articles {
+		if a.ID != story.LeadArticle.ID {
+			titles = append(titles, a.Title)
+		}
+	}
+	return mastodon.KeywordsForStory(titles, currentCorpus())
+}
+
+// topStoryKeywords returns the Mastodon keywords of the top stories of the front page
+func topStoryKeywords() []string {
+	refreshCorpus()
+
+	stories, err := frontPage()
+	if err != nil {
+		log.Printf("⚠️ Could not load stories for Mastodon trends: %v", err)
+		return nil
+	}
+
+	var keywords []string
+	for i := 0; i < len(stories) && i < mastodonConfig.Keywords; i++ {
+		keywords = append(keywords, storyKeyword(stories[i]))
+	}
+	return keywords
+}
+
+// cachedTrends merges the cached posts of the top stories' keywords, without
+// repeating posts, and returns the hashtags that had any
+func cachedTrends(stories []models.Story) ([]*gomastodon.Status, []string) {
+	var posts []*gomastodon.Status
+	var tags []string
+	seen := make(map[gomastodon.ID]bool)
+
+	for i := 0; i < len(stories) && i < mastodonConfig.Keywords; i++ {
+		keyword := storyKeyword(stories[i])
+		found := false
+		for _, p := range trendCache.Get(keyword) {
+			if seen[p.ID] || len(posts) == maxTrendPosts {
+				continue
+			}
+			seen[p.ID] = true
+			posts = append(posts, p)
+			found = true
+		}
+		if found {
+			tags = append(tags, "#"+keyword)
+		}
+	}
+	return posts, tags
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"log"
 	"os"
 	"vidit/internal/database"
@@ -27,6 +28,9 @@ func main() {
 		Type:     "rss", // START AS RSS
 		Category: "latam",
 		ColorHex: "#FFA500",
+
+		// Type switches are opt-in
+		AutoSwitchType: true,
 	}
 
 	if os.Getenv("NEWSAPI_KEY") == "" {
@@ -42,7 +46,7 @@ func main() {
 	// 3. Run Fetcher
 	log.Println("🔹 Running fetcher (Expect fallback to NewsAPI)...")
 	service := fetcher.NewService()
-	articles := service.FetchFeed(testFeed)
+	articles := service.FetchFeed(context.Background(), testFeed)
 
 	if len(articles) == 0 {
 		log.Fatal("❌ Failed to fetch articles (Fallback broken?)")
@@ -63,6 +67,13 @@ func main() {
 		log.Fatalf("❌ FAILURE! Feed type remained '%s'.", updatedFeed.Type)
 	}
 
+	var change models.FeedTypeChange
+	if err := db.Where("feed_id = ?", testFeed.ID).Last(&change).Error; err != nil {
+		log.Fatalf("❌ FAILURE! Type change was not recorded: %v", err)
+	}
+	log.Printf("✅ Recorded change: %s (%s) -> %s (%s)", change.FromType, change.FromURL, change.ToType, change.ToURL)
+
 	// Cleanup
+	db.Where("feed_id = ?", testFeed.ID).Delete(&models.FeedTypeChange{})
 	db.Delete(&updatedFeed)
 }
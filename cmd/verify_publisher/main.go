@@ -0,0 +1,248 @@
+// verify_publisher runs the Mastodon publisher end to end against an in-process fake
+// instance: dry run, posting, the hourly cap, one post per story and the post language.
+// It needs the database (DB_* variables); everything it writes is rolled back.
+//
+//	go run ./cmd/verify_publisher
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"time"
+	"vidit/internal/database"
+	"vidit/internal/mastodon"
+	"vidit/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/logger"
+)
+
+// request is a status received by the fake instance
+type request struct {
+	Status     string
+	Visibility string
+	Language   string
+}
+
+type fakeInstance struct {
+	mu       sync.Mutex
+	requests []request
+	reject   bool // Answer 422 to every post
+}
+
+func (f *fakeInstance) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost || r.URL.Path != "/api/v1/statuses" {
+		http.NotFound(w, r)
+		return
+	}
+	r.ParseForm()
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	w.Header().Set("Content-Type", "application/json")
+	if f.reject {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Validation failed"})
+		return
+	}
+	f.requests = append(f.requests, request{
+		Status:     r.PostForm.Get("status"),
+		Visibility: r.PostForm.Get("visibility"),
+		Language:   r.PostForm.Get("language"),
+	})
+	id := fmt.Sprint(len(f.requests))
+	json.NewEncoder(w).Encode(map[string]string{"id": id, "url": "https://fake.invalid/@vidit/" + id})
+}
+
+func (f *fakeInstance) setReject(reject bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.reject = reject
+}
+
+func (f *fakeInstance) received() []request {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]request(nil), f.requests...)
+}
+
+var failed int
+
+func check(ok bool, format string, args ...interface{}) {
+	if ok {
+		log.Printf("✅ "+format, args...)
+	} else {
+		log.Printf("❌ "+format, args...)
+		failed++
+	}
+}
+
+func main() {
+	dbConfig := database.Config{
+		Host:     getEnv("DB_HOST", "localhost"),
+		Port:     getEnv("DB_PORT", "5432"),
+		User:     getEnv("DB_USER", "postgres"),
+		Password: getEnv("DB_PASSWORD", "postgres"),
+		DBName:   getEnv("DB_NAME", "vidit"),
+		SSLMode:  getEnv("DB_SSLMODE", "disable"),
+	}
+	if err := database.Connect(dbConfig); err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+	if err := database.Migrate(); err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+
+	// Nothing is committed, and the running server never sees the test stories
+	tx := database.DB.Session(&gorm.Session{Logger: logger.Default.LogMode(logger.Silent)}).Begin()
+	defer tx.Rollback()
+
+	stories, err := seedStories(tx)
+	if err != nil {
+		log.Fatalf("❌ Failed to create the test stories: %v", err)
+	}
+
+	var base int64
+	tx.Model(&models.MastodonPost{}).Where("created_at > ?", time.Now().Add(-time.Hour)).Count(&base)
+
+	fake := &fakeInstance{}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+	service := mastodon.NewServiceWithConfig(mastodon.Config{
+		Server:      server.URL,
+		AccessToken: "test",
+		Timeout:     5 * time.Second,
+	})
+
+	cfg := mastodon.PublisherConfig{
+		Enabled:    true,
+		MinScore:   1e6, // Only the test stories qualify
+		MinSources: 3,
+		MaxAge:     time.Hour,
+		Visibility: "unlisted",
+		Hashtags:   1,
+	}
+	ctx := context.Background()
+	publish := func(dryRun bool, maxPerHour int) int {
+		c := cfg
+		c.DryRun = dryRun
+		c.MaxPerHour = int(base) + maxPerHour
+		n, err := mastodon.NewPublisher(tx, service, c).Publish(ctx)
+		if err != nil {
+			log.Fatalf("❌ Publish failed: %v", err)
+		}
+		return n
+	}
+
+	log.Println("🔹 Dry run...")
+	n := publish(true, 1)
+	var post models.MastodonPost
+	tx.Where("story_id = ?", stories[0].ID).First(&post)
+	check(n == 1 && len(fake.received()) == 0 && post.DryRun && post.StatusID == "",
+		"Dry run recorded story %d without calling the instance", stories[0].ID)
+
+	log.Println("🔹 Rejected post...")
+	fake.setReject(true)
+	n = publish(false, 2)
+	fake.setReject(false)
+	var claims int64
+	tx.Model(&models.MastodonPost{}).Where("story_id = ?", stories[1].ID).Count(&claims)
+	check(n == 0 && claims == 0, "A rejected post releases its claim")
+
+	log.Println("🔹 Posting under the hourly cap...")
+	n = publish(false, 3) // The dry run already used one
+	sent := fake.received()
+	check(n == 2 && len(sent) == 2, "Posted 2 stories, the cap's remainder (got %d)", len(sent))
+	if len(sent) == 2 {
+		check(sent[0].Language == "es" && sent[1].Language == "en",
+			"Languages taken from the lead articles: %q, %q", sent[0].Language, sent[1].Language)
+		check(sent[0].Visibility == "unlisted", "Visibility sent: %q", sent[0].Visibility)
+	}
+	post = models.MastodonPost{}
+	tx.Where("story_id = ?", stories[1].ID).First(&post)
+	check(post.StatusID == "1" && !post.DryRun, "Status ID stored for story %d", stories[1].ID)
+
+	check(publish(false, 3) == 0, "Nothing else goes out once the cap is reached")
+
+	log.Println("🔹 Raising the cap...")
+	n = publish(false, 10)
+	sent = fake.received()
+	check(n == 1 && len(sent) == 3, "Only the remaining story was posted, none twice (%d requests)", len(sent))
+	if len(sent) == 3 {
+		check(sent[2].Language == "", "Lead without a language sends none: %q", sent[2].Language)
+	}
+
+	result := tx.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&models.MastodonPost{StoryID: stories[2].ID, Text: "duplicate"})
+	check(result.Error == nil && result.RowsAffected == 0, "A second claim on a story is ignored")
+
+	if failed > 0 {
+		log.Printf("❌ %d checks failed", failed)
+		tx.Rollback()
+		os.Exit(1)
+	}
+	log.Println("✅ Publisher verified")
+}
+
+// seedStories creates four fresh stories covered by three feeds, by descending score.
+// The leads declare "es" (dry run), "es-CL", "en" and no language.
+func seedStories(tx *gorm.DB) ([]models.Story, error) {
+	stamp := time.Now().UnixNano()
+	now := time.Now()
+
+	var feeds []models.Feed
+	for i, name := range []string{"Verificación A", "Verificación B", "Verificación C"} {
+		feed := models.Feed{Name: name, URL: fmt.Sprintf("https://verify-publisher.invalid/%d/feed%d", stamp, i)}
+		if err := tx.Create(&feed).Error; err != nil {
+			return nil, err
+		}
+		feeds = append(feeds, feed)
+	}
+
+	var stories []models.Story
+	for i, lang := range []string{"es", "es-CL", "en", ""} {
+		var articles []models.Article
+		for j, feed := range feeds {
+			article := models.Article{
+				Title:       fmt.Sprintf("Noticia de prueba %d desde %s", i, feed.Name),
+				URL:         fmt.Sprintf("https://verify-publisher.invalid/%d/story%d/%d", stamp, i, j),
+				PublishedAt: now,
+				Language:    lang,
+				FeedID:      feed.ID,
+			}
+			if err := tx.Create(&article).Error; err != nil {
+				return nil, err
+			}
+			articles = append(articles, article)
+		}
+
+		story := models.Story{
+			Title:         articles[0].Title,
+			Score:         1e6 + float64(4-i),
+			ArticleCount:  len(articles),
+			FirstSeenAt:   now,
+			LastSeenAt:    now,
+			LeadArticleID: articles[0].ID,
+			Articles:      articles,
+		}
+		if err := tx.Omit("Articles.*").Create(&story).Error; err != nil {
+			return nil, err
+		}
+		stories = append(stories, story)
+	}
+	return stories, nil
+}
+
+func getEnv(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return def
+}
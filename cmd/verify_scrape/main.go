@@ -0,0 +1,103 @@
+// verify_scrape runs the rules of each sample page in internal/fetcher/testdata/scrape
+// and checks the extracted headlines (title, link and date). Run it from the repository root:
+//
+//	go run ./cmd/verify_scrape
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+	"vidit/internal/fetcher"
+	"vidit/internal/models"
+)
+
+const (
+	fixtures = "internal/fetcher/testdata/scrape"
+	pageURL  = "https://www.example.cl/"
+)
+
+type item struct {
+	Title string
+	URL   string
+	Date  string // RFC 3339, empty for undated headlines
+}
+
+var expected = map[string][]item{
+	// Links around and inside titles, datetime attributes, and the
+	// ad without a link and the repeated headline left out
+	"articles": {
+		{"Senado aprueba en general la reforma de pensiones", "https://www.example.cl/politica/2026/10/18/senado-aprueba-reforma-de-pensiones.html", "2026-10-18T09:30:00-03:00"},
+		{"IPC de septiembre sube 0,4% y supera lo esperado", "https://www.example.cl/economia/2026/10/18/ipc-septiembre", "2026-10-18T08:00:00-03:00"},
+		{"Bolivia define su segunda vuelta presidencial", "https://www.example.cl/mundo/2026/10/17/elecciones-en-bolivia", ""},
+	},
+	// A Latin-1 page with Spanish text dates in America/Santiago
+	"cards": {
+		{"Incendio forestal obliga a evacuar sectores altos de Valparaíso", "https://www.example.cl/noticias/nacional/2026/10/18/1178001/incendio-forestal-valparaiso.html", "2026-10-18T10:15:00-03:00"},
+		{"Precio del cobre anota su mayor alza en tres meses", "https://www.example.cl/noticias/economia/2026/10/18/1178002/cobre-precio.html", "2026-10-18T09:40:00-03:00"},
+		{"La Roja entrega nómina para las clasificatorias con tres novedades", "https://www.example.cl/noticias/deportes/2026/10/17/1177990/la-roja-nomina.html", ""},
+	},
+}
+
+func main() {
+	failed := 0
+	for _, name := range []string{"articles", "cards"} {
+		articles, err := scrape(name)
+		if err != nil {
+			log.Printf("❌ %s: %v", name, err)
+			failed++
+			continue
+		}
+		if ok := compare(name, articles, expected[name]); !ok {
+			failed++
+			continue
+		}
+		log.Printf("✅ %s: %d headlines as expected", name, len(articles))
+	}
+
+	if failed > 0 {
+		log.Printf("❌ %d sample pages failed", failed)
+		os.Exit(1)
+	}
+}
+
+func scrape(name string) ([]models.Article, error) {
+	data, err := os.ReadFile(filepath.Join(fixtures, name+".json"))
+	if err != nil {
+		return nil, err
+	}
+	var rules models.ScrapeRules
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return nil, err
+	}
+
+	page, err := os.Open(filepath.Join(fixtures, name+".html"))
+	if err != nil {
+		return nil, err
+	}
+	defer page.Close()
+	return fetcher.ScrapeArticles(page, "text/html", pageURL, rules)
+}
+
+// compare logs every difference between the extracted and the expected headlines
+func compare(name string, articles []models.Article, want []item) bool {
+	ok := len(articles) == len(want)
+	if !ok {
+		log.Printf("❌ %s: %d headlines, want %d", name, len(articles), len(want))
+	}
+
+	for i := 0; i < len(articles) && i < len(want); i++ {
+		a, w := articles[i], want[i]
+		date := ""
+		if !a.PublishedAt.IsZero() {
+			date = a.PublishedAt.Format(time.RFC3339)
+		}
+		if a.Title != w.Title || a.URL != w.URL || date != w.Date {
+			log.Printf("❌ %s #%d:\n   got  %q %s [%s]\n   want %q %s [%s]", name, i+1, a.Title, a.URL, date, w.Title, w.URL, w.Date)
+			ok = false
+		}
+	}
+	return ok
+}
@@ -6,6 +6,7 @@ import (
 	"html/template"
 	"log"
 	"time"
+	"vidit/internal/fetcher"
 	"vidit/internal/models"
 )
 
@@ -18,6 +19,13 @@ func main() {
 		"formatScore": func(score float64) string {
 			return fmt.Sprintf("%.2f", score)
 		},
+		"scoreBreakdown": func(article models.Article, now time.Time) string {
+			rs := &fetcher.RankingService{Now: now}
+			return rs.Breakdown(article).String()
+		},
+		"safeHTML": func(s string) template.HTML {
+			return template.HTML(s)
+		},
 	}
 
 	tmpl, err := template.New("").Funcs(funcMap).ParseGlob("views/*.html")
@@ -26,11 +34,14 @@ func main() {
 	}
 
 	// Mock Data
+	lead := models.Article{Title: "Test Article", Score: 1.23456, Feed: models.Feed{Type: "rss", Name: "TestFeed"}}
 	data := map[string]interface{}{
-		"Articles": []models.Article{
-			{Title: "Test Article", Score: 1.23456, Feed: models.Feed{Type: "rss", Name: "TestFeed"}},
+		"Stories": []models.Story{
+			{Title: lead.Title, Score: lead.Score, LeadArticle: lead, Articles: []models.Article{lead}},
 		},
-		"Count": 1,
+		"Count":    1,
+		"Now":      time.Now(),
+		"Sections": []Section{{Label: "CL", Path: "/country/CL"}},
 	}
 
 	var buf bytes.Buffer
@@ -46,4 +57,87 @@ func main() {
 	} else {
 		log.Printf("❌ Failure: Score 1.23 not found in rendered output.\nSample output: %s", output[:500])
 	}
+
+	// Check if the score breakdown tooltip is rendered
+	if bytes.Contains(buf.Bytes(), []byte("Tipo 1.50 × confianza 1.00")) {
+		log.Println("✅ Success: Score breakdown found in rendered HTML.")
+	} else {
+		log.Println("❌ Failure: Score breakdown not found in rendered output.")
+	}
+
+	// Search results: highlighted title and the filters bar
+	searchData := map[string]interface{}{
+		"Query":      "elecciones",
+		"Search":     true,
+		"Feeds":      []models.Feed{{ID: 1, Name: "TestFeed"}},
+		"FeedIDs":    map[uint]bool{1: true},
+		"Stories":    data["Stories"],
+		"Highlights": map[uint]Highlight{0: {Title: "<mark>Test</mark> Article"}},
+		"Count":      1,
+		"Total":      int64(61),
+		"Now":        time.Now(),
+		"Page":       1,
+		"NextURL":    "/search?page=2&q=elecciones",
+	}
+	buf.Reset()
+	if err := tmpl.ExecuteTemplate(&buf, "index.html", searchData); err != nil {
+		log.Fatalf("❌ Search template execution failed: %v", err)
+	}
+	if bytes.Contains(buf.Bytes(), []byte("<mark>Test</mark> Article")) && bytes.Contains(buf.Bytes(), []byte("Más resultados")) &&
+		bytes.Contains(buf.Bytes(), []byte("61 resultados para «elecciones»")) && !bytes.Contains(buf.Bytes(), []byte("keywordSearch.addEventListener")) {
+		log.Println("✅ Success: Search highlights, total and pagination found in rendered HTML.")
+	} else {
+		log.Println("❌ Failure: Search highlights not found in rendered output.")
+	}
+
+	// Archive day: previous/next navigation above the mosaic
+	archiveData := map[string]interface{}{
+		"Stories":   data["Stories"],
+		"Count":     1,
+		"Now":       lead.PublishedAt.Add(10 * time.Hour), // Scores are computed at the end of the day
+		"Archive":   true,
+		"DateLabel": "Lunes 6 de Octubre de 2025",
+		"PrevDay":   "2025-10-05",
+	}
+	buf.Reset()
+	if err := tmpl.ExecuteTemplate(&buf, "index.html", archiveData); err != nil {
+		log.Fatalf("❌ Archive template execution failed: %v", err)
+	}
+	if bytes.Contains(buf.Bytes(), []byte(`href="/archive/2025-10-05"`)) && bytes.Contains(buf.Bytes(), []byte("(10.0h")) {
+		log.Println("✅ Success: Archive navigation and day-end breakdown found in rendered HTML.")
+	} else {
+		log.Println("❌ Failure: Archive navigation not found in rendered output.")
+	}
+
+	// Section page: navigation with the current section marked
+	sectionData := map[string]interface{}{
+		"Stories":     data["Stories"],
+		"Count":       1,
+		"Now":         time.Now(),
+		"Section":     "Ciberseguridad",
+		"SectionPath": "/c/cybersecurity",
+		"SectionFeed": "/feed/cybersecurity.rss",
+		"Sections":    []Section{{Label: "Ciberseguridad", Path: "/c/cybersecurity"}, {Label: "CL", Path: "/country/CL"}},
+	}
+	buf.Reset()
+	if err := tmpl.ExecuteTemplate(&buf, "index.html", sectionData); err != nil {
+		log.Fatalf("❌ Section template execution failed: %v", err)
+	}
+	if bytes.Contains(buf.Bytes(), []byte(`<a href="/c/cybersecurity" class="is-active">`)) {
+		log.Println("✅ Success: Section navigation found in rendered HTML.")
+	} else {
+		log.Println("❌ Failure: Section navigation not found in rendered output.")
+	}
+}
+
+// Section mirrors the navigation links the server passes to the template
+type Section struct {
+	Label string
+	Path  string
+}
+
+// Highlight mirrors the type the search handler passes to the template
+type Highlight struct {
+	Title   template.HTML
+	Summary template.HTML
 }
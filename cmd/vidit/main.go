@@ -0,0 +1,241 @@
+// Command vidit groups the maintenance tasks of the feeds:
+//
+//	go run ./cmd/vidit feeds validate                          # every feed in the database
+//	go run ./cmd/vidit feeds validate -type sitemap            # only sitemap feeds
+//	go run ./cmd/vidit feeds validate -name Emol,BioBioChile   # some feeds, by name
+//	go run ./cmd/vidit feeds validate -format json cmd/seed/candidates/chile.txt
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+	"vidit/internal/database"
+	"vidit/internal/fetcher"
+	"vidit/internal/models"
+)
+
+const usage = `usage: vidit <command> [flags]
+
+commands:
+  feeds validate [-name a,b] [-type t] [-format table|json] [-fallbacks] [file]
+      Fetch feeds with their real sources, without saving anything, and report
+      status, parse result, items, newest item age, gossip ratio and warnings.
+      Feeds come from the database, or from a candidates file (.json with a list
+      of feeds, or text with "url [type] [name]" per line).`
+
+func main() {
+	if len(os.Args) < 3 || os.Args[1] != "feeds" || os.Args[2] != "validate" {
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+	os.Exit(validateCmd(os.Args[3:]))
+}
+
+func validateCmd(args []string) int {
+	fs := flag.NewFlagSet("feeds validate", flag.ExitOnError)
+	names := fs.String("name", "", "Comma-separated names of the feeds to validate")
+	feedType := fs.String("type", "", "Only validate feeds of this type")
+	format := fs.String("format", "table", "Output format: table or json")
+	fallbacks := fs.Bool("fallbacks", false, "Also try the feeds' fallback sources")
+	workers := fs.Int("workers", fetcher.DefaultWorkers, "Feeds validated at the same time")
+	fs.Parse(args)
+
+	if *format != "table" && *format != "json" {
+		fmt.Fprintln(os.Stderr, usage)
+		return 2
+	}
+
+	var feeds []models.Feed
+	var err error
+	if fs.NArg() > 0 {
+		feeds, err = loadCandidates(fs.Arg(0))
+	} else {
+		feeds, err = loadDBFeeds()
+	}
+	if err != nil {
+		log.Printf("❌ %v", err)
+		return 1
+	}
+
+	feeds = filterFeeds(feeds, *names, *feedType)
+	if len(feeds) == 0 {
+		log.Println("⚠️  No feeds to validate")
+		return 1
+	}
+
+	log.Printf("🔍 Validating %d feeds...", len(feeds))
+	reports := fetcher.ValidateFeeds(context.Background(), feeds, fetcher.ValidateOptions{
+		Fallbacks: *fallbacks,
+		Workers:   *workers,
+	})
+
+	if *format == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(reports)
+	} else {
+		printReports(reports)
+	}
+
+	failed := 0
+	for _, r := range reports {
+		if !r.OK() {
+			failed++
+		}
+	}
+	log.Printf("📊 %d OK, %d failed", len(reports)-failed, failed)
+	if failed > 0 {
+		return 1
+	}
+	return 0
+}
+
+func printReports(reports []fetcher.FeedReport) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "OK\tNAME\tTYPE\tSTATUS\tPARSE\tITEMS\tNEWEST\tGOSSIP\tMS\tWARNINGS")
+	for _, r := range reports {
+		ok, status, newest := "❌", "—", "—"
+		if r.OK() {
+			ok = "✅"
+		}
+		if r.StatusCode != 0 {
+			status = fmt.Sprint(r.StatusCode)
+		}
+		if r.Newest != nil {
+			newest = formatAge(r.NewestAge())
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%d\t%s\t%.0f%%\t%d\t%s\n",
+			ok, r.Name, r.Type, status, truncate(r.Parse, 60), r.Items, newest,
+			r.GossipRatio*100, r.LatencyMs, strings.Join(r.Warnings, "; "))
+	}
+	w.Flush()
+}
+
+// loadDBFeeds returns the feeds in the database, auto-disabled ones included
+func loadDBFeeds() ([]models.Feed, error) {
+	dbConfig := database.Config{
+		Host:     getEnv("DB_HOST", "localhost"),
+		Port:     getEnv("DB_PORT", "5432"),
+		User:     getEnv("DB_USER", "postgres"),
+		Password: getEnv("DB_PASSWORD", "postgres"),
+		DBName:   getEnv("DB_NAME", "vidit"),
+		SSLMode:  getEnv("DB_SSLMODE", "disable"),
+	}
+	if err := database.Connect(dbConfig); err != nil {
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+
+	var feeds []models.Feed
+	if err := database.DB.Order("name").Find(&feeds).Error; err != nil {
+		return nil, err
+	}
+	return feeds, nil
+}
+
+// loadCandidates reads feeds from a .json list of feeds (or the output of
+// discover -json), or from a text file with one "url [type] [name]" per line;
+// words starting with # are comments. Without a type, URLs containing "sitemap"
+// are sitemaps and the rest RSS.
+func loadCandidates(path string) ([]models.Feed, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var feeds []models.Feed
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		var items []struct {
+			models.Feed
+			Candidate *models.Feed `json:"feed"` // discover -json wraps each feed
+		}
+		if err := json.Unmarshal(data, &items); err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		for _, item := range items {
+			if item.Candidate != nil {
+				item.Feed = *item.Candidate
+			}
+			feeds = append(feeds, item.Feed)
+		}
+		return feeds, nil
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(string(data)))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		for i, f := range fields {
+			if strings.HasPrefix(f, "#") {
+				fields = fields[:i]
+				break
+			}
+		}
+		if len(fields) == 0 {
+			continue
+		}
+
+		feed := models.Feed{URL: fields[0], Name: fields[0], Type: "rss"}
+		if strings.Contains(strings.ToLower(feed.URL), "sitemap") {
+			feed.Type = "sitemap"
+		}
+		if len(fields) > 1 {
+			feed.Type = fields[1]
+		}
+		if len(fields) > 2 {
+			feed.Name = strings.Join(fields[2:], " ")
+		}
+		feeds = append(feeds, feed)
+	}
+	return feeds, nil
+}
+
+func filterFeeds(feeds []models.Feed, names, feedType string) []models.Feed {
+	wanted := make(map[string]bool)
+	for _, n := range strings.Split(names, ",") {
+		if n = strings.TrimSpace(n); n != "" {
+			wanted[strings.ToLower(n)] = true
+		}
+	}
+
+	var filtered []models.Feed
+	for _, f := range feeds {
+		if len(wanted) > 0 && !wanted[strings.ToLower(f.Name)] {
+			continue
+		}
+		if feedType != "" && f.Type != feedType {
+			continue
+		}
+		filtered = append(filtered, f)
+	}
+	return filtered
+}
+
+// formatAge is a duration rounded to minutes, or in days once it is over two
+func formatAge(d time.Duration) string {
+	if d > 48*time.Hour {
+		return fmt.Sprintf("%dd", int(d.Hours()/24))
+	}
+	return d.Round(time.Minute).String()
+}
+
+func truncate(s string, n int) string {
+	if r := []rune(s); len(r) > n {
+		return string(r[:n-1]) + "…"
+	}
+	return s
+}
+
+func getEnv(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return def
+}
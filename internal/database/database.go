@@ -44,15 +44,50 @@ func Connect(cfg Config) error {
 
 // Migrate runs auto-migration for all models
 func Migrate() error {
+	// Custom join table so an article can only belong to one story
+	if err := DB.SetupJoinTable(&models.Story{}, "Articles", &models.StoryArticle{}); err != nil {
+		return fmt.Errorf("join table setup failed: %w", err)
+	}
+
 	err := DB.AutoMigrate(
 		&models.Feed{},
 		&models.Article{},
+		&models.Story{},
+		&models.StoryArticle{},
+		&models.FeedFetchLog{},
+		&models.RankingConfig{},
+		&models.MastodonPost{},
+		&models.FeedTypeChange{},
 	)
 	
 	if err != nil {
 		return fmt.Errorf("migration failed: %w", err)
 	}
 	
+	if err := migrateSearch(); err != nil {
+		return fmt.Errorf("search migration failed: %w", err)
+	}
+	
 	log.Println("✅ Database migration completed")
 	return nil
 }
+
+// migrateSearch adds the full-text search column (title weighted over summary)
+// and its GIN index. The column is generated, so it is never written by GORM.
+func migrateSearch() error {
+	statements := []string{
+		`ALTER TABLE articles ADD COLUMN IF NOT EXISTS search_vector tsvector
+			GENERATED ALWAYS AS (
+				setweight(to_tsvector('spanish', coalesce(title, '')), 'A') ||
+				setweight(to_tsvector('spanish', coalesce(summary, '')), 'B')
+			) STORED`,
+		`CREATE INDEX IF NOT EXISTS idx_articles_search_vector ON articles USING GIN (search_vector)`,
+	}
+	
+	for _, stmt := range statements {
+		if err := DB.Exec(stmt).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
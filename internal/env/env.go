@@ -0,0 +1,41 @@
+// Package env reads typed settings from the environment. Unset or invalid
+// values fall back to the given default, so callers only state their defaults.
+package env
+
+import (
+	"os"
+	"strconv"
+	"time"
+)
+
+// Int reads an integer, keeping def when it is unset, invalid or below min
+func Int(key string, def, min int) int {
+	if v, err := strconv.Atoi(os.Getenv(key)); err == nil && v >= min {
+		return v
+	}
+	return def
+}
+
+// Float reads a number, keeping def when it is unset, invalid or below min
+func Float(key string, def, min float64) float64 {
+	if v, err := strconv.ParseFloat(os.Getenv(key), 64); err == nil && v >= min {
+		return v
+	}
+	return def
+}
+
+// Duration reads a Go duration ("90s", "5m"), keeping def when it is unset, invalid or below min
+func Duration(key string, def, min time.Duration) time.Duration {
+	if v, err := time.ParseDuration(os.Getenv(key)); err == nil && v >= min {
+		return v
+	}
+	return def
+}
+
+// Bool reads a boolean ("true", "1", "false"...), keeping def when it is unset or invalid
+func Bool(key string, def bool) bool {
+	if v, err := strconv.ParseBool(os.Getenv(key)); err == nil {
+		return v
+	}
+	return def
+}
@@ -0,0 +1,136 @@
+package fetcher
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"vidit/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// RankingConfigReload is how often the server checks the ranking_configs row for changes
+const RankingConfigReload = 1 * time.Minute
+
+const rankingConfigID = 1
+
+var rankingConfig atomic.Pointer[models.RankingConfig]
+
+// DefaultRankingConfig is used until the ranking_configs row is loaded, and to create it
+func DefaultRankingConfig() models.RankingConfig {
+	return models.RankingConfig{
+		ID:               rankingConfigID,
+		WeightRSS:        1.5,
+		WeightSitemap:    1.2,
+		WeightAPI:        1.0,
+		WeightCluster:    2.5,
+		GravityDecay:     1.8,
+		ThresholdCluster: 0.4,
+		ThresholdDedup:   0.35,
+		CountryBoosts:    map[string]float64{"CL": 1.0}, // Boost Chilean sources
+		CategoryBoosts:   map[string]float64{},
+	}
+}
+
+// CurrentRankingConfig returns the configuration in use
+func CurrentRankingConfig() models.RankingConfig {
+	if cfg := rankingConfig.Load(); cfg != nil {
+		return *cfg
+	}
+	return DefaultRankingConfig()
+}
+
+// LoadRankingConfig reads the configuration row (creating it with the defaults if
+// missing) and makes it the one in use
+func LoadRankingConfig(db *gorm.DB) (models.RankingConfig, error) {
+	cfg := DefaultRankingConfig()
+	if err := db.Where("id = ?", rankingConfigID).FirstOrCreate(&cfg).Error; err != nil {
+		return CurrentRankingConfig(), err
+	}
+	// Rows created before boosts existed
+	if cfg.CountryBoosts == nil {
+		cfg.CountryBoosts = DefaultRankingConfig().CountryBoosts
+	}
+	normalizeBoosts(&cfg)
+	if err := ValidateRankingConfig(cfg); err != nil {
+		return CurrentRankingConfig(), err
+	}
+
+	rankingConfig.Store(&cfg)
+	return cfg, nil
+}
+
+// SaveRankingConfig validates and stores a new configuration, which takes effect immediately
+func SaveRankingConfig(db *gorm.DB, cfg models.RankingConfig) (models.RankingConfig, error) {
+	if err := ValidateRankingConfig(cfg); err != nil {
+		return cfg, err
+	}
+
+	cfg.ID = rankingConfigID
+	normalizeBoosts(&cfg)
+	if err := db.Save(&cfg).Error; err != nil {
+		return cfg, err
+	}
+
+	rankingConfig.Store(&cfg)
+	log.Println("⚖️  Ranking configuration updated")
+	return cfg, nil
+}
+
+func ValidateRankingConfig(cfg models.RankingConfig) error {
+	weights := []float64{cfg.WeightRSS, cfg.WeightSitemap, cfg.WeightAPI, cfg.WeightCluster}
+	for _, w := range weights {
+		if w < 0 {
+			return errors.New("weights cannot be negative")
+		}
+	}
+	if cfg.GravityDecay <= 0 {
+		return errors.New("gravity_decay must be greater than 0")
+	}
+	if cfg.ThresholdCluster <= 0 || cfg.ThresholdCluster > 1 || cfg.ThresholdDedup <= 0 || cfg.ThresholdDedup > 1 {
+		return errors.New("thresholds must be between 0 and 1")
+	}
+	return nil
+}
+
+// normalizeBoosts matches the casing used by feeds: countries upper case, categories lower case
+func normalizeBoosts(cfg *models.RankingConfig) {
+	countries := make(map[string]float64, len(cfg.CountryBoosts))
+	for k, v := range cfg.CountryBoosts {
+		countries[strings.ToUpper(strings.TrimSpace(k))] = v
+	}
+	categories := make(map[string]float64, len(cfg.CategoryBoosts))
+	for k, v := range cfg.CategoryBoosts {
+		categories[strings.ToLower(strings.TrimSpace(k))] = v
+	}
+	cfg.CountryBoosts, cfg.CategoryBoosts = countries, categories
+}
+
+// WatchRankingConfig reloads the configuration every RankingConfigReload, so edits
+// made from another instance or directly in the database are picked up without a restart
+func WatchRankingConfig(ctx context.Context, db *gorm.DB) {
+	ticker := time.NewTicker(RankingConfigReload)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		previous := CurrentRankingConfig()
+		cfg, err := LoadRankingConfig(db)
+		if err != nil {
+			log.Printf("❌ Error reloading ranking configuration: %v\n", err)
+			continue
+		}
+		if !cfg.UpdatedAt.Equal(previous.UpdatedAt) {
+			log.Println("⚖️  Ranking configuration reloaded")
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package fetcher
+
+import (
+	"net/url"
+	"strings"
+	"unicode"
+
+	"github.com/mmcdole/gofeed"
+	"golang.org/x/net/html"
+)
+
+// Limits for the metadata stored with each article
+const (
+	SummaryMaxLength = 400 // Runes
+	maxCategories    = 10
+)
+
+// skippedElements never contribute text to a summary
+var skippedElements = map[string]bool{
+	"script": true, "style": true, "noscript": true, "iframe": true, "object": true,
+	"embed": true, "svg": true, "form": true, "button": true, "figcaption": true,
+}
+
+// plainText strips every tag from an HTML fragment, decodes entities, collapses
+// whitespace and cuts the result at a word boundary after max runes
+func plainText(fragment string, max int) string {
+	if fragment == "" {
+		return ""
+	}
+
+	var b strings.Builder
+	z := html.NewTokenizer(strings.NewReader(fragment))
+	skip := 0
+
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			return truncate(collapseSpaces(b.String()), max)
+		case html.StartTagToken:
+			name, _ := z.TagName()
+			if skippedElements[string(name)] {
+				skip++
+			}
+			b.WriteByte(' ')
+		case html.EndTagToken:
+			name, _ := z.TagName()
+			if skippedElements[string(name)] && skip > 0 {
+				skip--
+			}
+			b.WriteByte(' ')
+		case html.SelfClosingTagToken:
+			b.WriteByte(' ')
+		case html.TextToken:
+			if skip == 0 {
+				b.Write(z.Text())
+			}
+		}
+	}
+}
+
+func collapseSpaces(s string) string {
+	return strings.Join(strings.FieldsFunc(s, unicode.IsSpace), " ")
+}
+
+// truncate cuts s after max runes, at the last space, adding an ellipsis
+func truncate(s string, max int) string {
+	runes := []rune(s)
+	if max <= 0 || len(runes) <= max {
+		return s
+	}
+
+	cut := string(runes[:max])
+	if i := strings.LastIndex(cut, " "); i > len(cut)/2 {
+		cut = cut[:i]
+	}
+	return strings.TrimRight(cut, " ,.;:-") + "…"
+}
+
+// safeURL returns raw resolved against base if it is an absolute http(s) URL,
+// or "" otherwise (javascript:, data:, garbage)
+func safeURL(raw, base string) string {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return ""
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil {
+		return ""
+	}
+	if !u.IsAbs() && base != "" {
+		b, err := url.Parse(base)
+		if err != nil {
+			return ""
+		}
+		u = b.ResolveReference(u)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return ""
+	}
+	return u.String()
+}
+
+// cleanCategories trims, de-duplicates and caps the list of categories
+func cleanCategories(categories []string) []string {
+	seen := make(map[string]bool)
+	var clean []string
+	for _, c := range categories {
+		c = collapseSpaces(plainText(c, 0))
+		key := strings.ToLower(c)
+		if c == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		clean = append(clean, c)
+		if len(clean) == maxCategories {
+			break
+		}
+	}
+	return clean
+}
+
+// itemSummary prefers the description (usually the lead) over the full content
+func itemSummary(item *gofeed.Item) string {
+	if summary := plainText(item.Description, SummaryMaxLength); summary != "" {
+		return summary
+	}
+	return plainText(item.Content, SummaryMaxLength)
+}
+
+func itemAuthor(item *gofeed.Item) string {
+	var names []string
+	for _, a := range item.Authors {
+		if a != nil && strings.TrimSpace(a.Name) != "" {
+			names = append(names, strings.TrimSpace(a.Name))
+		}
+	}
+	if len(names) == 0 && item.Author != nil {
+		names = append(names, strings.TrimSpace(item.Author.Name))
+	}
+	return plainText(strings.Join(names, ", "), 200)
+}
+
+// itemImage uses the image gofeed found (itunes, media:content, enclosures, first <img>),
+// falling back to media:thumbnail
+func itemImage(item *gofeed.Item) string {
+	if item.Image != nil {
+		if u := safeURL(item.Image.URL, item.Link); u != "" {
+			return u
+		}
+	}
+	if media, ok := item.Extensions["media"]; ok {
+		for _, e := range media["thumbnail"] {
+			if u := safeURL(e.Attrs["url"], item.Link); u != "" {
+				return u
+			}
+		}
+	}
+	return ""
+}
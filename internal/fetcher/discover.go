@@ -0,0 +1,274 @@
+package fetcher
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"math"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"vidit/internal/models"
+
+	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/net/html/charset"
+)
+
+// Paths probed when the homepage doesn't declare its feeds
+var commonFeedPaths = []string{
+	"/feed", "/feed/", "/rss", "/rss/", "/rss.xml", "/feed.xml", "/atom.xml", "/index.xml",
+	"/arc/outboundfeeds/rss/",
+}
+
+// Usual locations of news sitemaps, probed besides the ones listed in robots.txt
+var commonSitemapPaths = []string{
+	"/sitemap_news.xml", "/sitemap-news.xml", "/news-sitemap.xml",
+	"/arc/outboundfeeds/sitemap-news.xml", "/sitemap.xml", "/sitemap_index.xml",
+}
+
+const (
+	maxRobotsSitemaps = 5 // Sitemaps taken from robots.txt, news ones first
+	discoveryWorkers  = 4
+)
+
+// countryByTLD guesses a feed's country from its domain
+var countryByTLD = map[string]string{
+	"cl": "CL", "es": "ES", "ar": "AR", "mx": "MX", "pe": "PE", "co": "CO", "uy": "UY",
+	"ve": "VE", "bo": "BO", "ec": "EC", "py": "PY", "cn": "CN", "uk": "GB", "br": "BR",
+}
+
+// Candidate is a feed found on a site, probed with its source
+type Candidate struct {
+	Feed       models.Feed `json:"feed"`   // Ready to save
+	Origin     string      `json:"origin"` // link, anchor, path, robots
+	Items      int         `json:"items"`
+	Newest     *time.Time  `json:"newest,omitempty"`
+	StatusCode int         `json:"status_code"`
+	Error      string      `json:"error,omitempty"`
+	Score      float64     `json:"score"` // log(1 + items) / √(hours since newest item + 2); 0 if it failed
+}
+
+// Works reports whether the probe returned items
+func (c Candidate) Works() bool {
+	return c.Error == "" && c.Items > 0
+}
+
+// Discover looks for the feeds of a site: <link rel="alternate"> and feed links of
+// the homepage, common feed paths, robots.txt sitemaps and usual news sitemap
+// locations. Every candidate is fetched once with its source; working ones come
+// first, fresher and larger ones before the rest.
+func (s *Service) Discover(ctx context.Context, siteURL string) ([]Candidate, error) {
+	if !strings.Contains(siteURL, "://") {
+		siteURL = "https://" + siteURL
+	}
+	site, err := url.Parse(siteURL)
+	if err != nil || site.Host == "" {
+		return nil, fmt.Errorf("invalid site URL %q", siteURL)
+	}
+
+	home, err := s.discoverHomepage(ctx, site.String())
+	if err != nil {
+		return nil, err
+	}
+
+	root := &url.URL{Scheme: home.base.Scheme, Host: home.base.Host}
+	var candidates []Candidate
+	seen := make(map[string]bool)
+	add := func(rawURL, kind, origin, name string) {
+		u := safeURL(rawURL, home.base.String())
+		if u == "" || seen[u] {
+			return
+		}
+		seen[u] = true
+		if name == "" {
+			name = home.title
+			if kind == "sitemap" {
+				name += " (Sitemap)"
+			}
+		}
+		candidates = append(candidates, Candidate{Feed: newCandidateFeed(name, u, kind, root.Host), Origin: origin})
+	}
+
+	for _, l := range home.feeds {
+		add(l.url, l.kind, l.origin, l.title)
+	}
+	if len(home.feeds) == 0 {
+		for _, p := range commonFeedPaths {
+			add(root.String()+p, "rss", "path", "")
+		}
+	}
+	for _, sm := range s.robotsSitemaps(ctx, root.String()) {
+		add(sm, "sitemap", "robots", "")
+	}
+	for _, p := range commonSitemapPaths {
+		add(root.String()+p, "sitemap", "path", "")
+	}
+
+	s.probeCandidates(ctx, candidates)
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].Works() != candidates[j].Works() {
+			return candidates[i].Works()
+		}
+		return candidates[i].Score > candidates[j].Score
+	})
+	return candidates, nil
+}
+
+type discoveredLink struct {
+	url, kind, origin, title string
+}
+
+type homepage struct {
+	base  *url.URL // After redirects
+	title string
+	feeds []discoveredLink
+}
+
+// discoverHomepage reads the site name and the feeds the homepage links to
+func (s *Service) discoverHomepage(ctx context.Context, siteURL string) (*homepage, error) {
+	resp, err := s.get(ctx, siteURL, "homepage", scrapeUserAgent, models.Feed{}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch homepage: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := charset.NewReader(resp.Body, resp.Header.Get("Content-Type"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode homepage: %w", err)
+	}
+	doc, err := goquery.NewDocumentFromReader(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse homepage: %w", err)
+	}
+
+	home := &homepage{base: resp.Request.URL}
+	home.title = strings.TrimSpace(doc.Find(`meta[property="og:site_name"]`).AttrOr("content", ""))
+	if home.title == "" {
+		home.title = collapseSpaces(strings.TrimSpace(doc.Find("title").First().Text()))
+	}
+	if home.title == "" {
+		home.title = strings.TrimPrefix(home.base.Hostname(), "www.")
+	}
+
+	doc.Find(`link[rel~="alternate"][href]`).Each(func(_ int, l *goquery.Selection) {
+		kind := ""
+		switch t := strings.ToLower(l.AttrOr("type", "")); {
+		case strings.Contains(t, "atom"):
+			kind = "atom"
+		case strings.Contains(t, "rss"), strings.Contains(t, "xml"):
+			kind = "rss"
+		}
+		if kind != "" {
+			title := strings.TrimSpace(l.AttrOr("title", ""))
+			home.feeds = append(home.feeds, discoveredLink{url: l.AttrOr("href", ""), kind: kind, origin: "link", title: title})
+		}
+	})
+
+	// Sites without <link> tags often still link to their feeds ("RSS" in the footer)
+	if len(home.feeds) == 0 {
+		doc.Find("a[href]").Each(func(_ int, a *goquery.Selection) {
+			href := strings.ToLower(a.AttrOr("href", ""))
+			if strings.HasSuffix(href, ".rss") || strings.HasSuffix(href, "/rss") || strings.HasSuffix(href, "/rss/") ||
+				strings.HasSuffix(href, "/feed") || strings.HasSuffix(href, "/feed/") {
+				home.feeds = append(home.feeds, discoveredLink{url: a.AttrOr("href", ""), kind: "rss", origin: "anchor"})
+			}
+		})
+	}
+
+	return home, nil
+}
+
+// robotsSitemaps returns the Sitemap: entries of robots.txt, news sitemaps first
+func (s *Service) robotsSitemaps(ctx context.Context, root string) []string {
+	resp, err := s.get(ctx, root+"/robots.txt", "robots.txt", scrapeUserAgent, models.Feed{}, nil)
+	if err != nil {
+		return nil
+	}
+	defer resp.Body.Close()
+
+	var news, other []string
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) < 8 || !strings.EqualFold(line[:8], "sitemap:") {
+			continue
+		}
+		loc := strings.TrimSpace(line[8:])
+		if strings.Contains(strings.ToLower(loc), "news") {
+			news = append(news, loc)
+		} else {
+			other = append(other, loc)
+		}
+	}
+
+	sitemaps := append(news, other...)
+	if len(sitemaps) > maxRobotsSitemaps {
+		sitemaps = sitemaps[:maxRobotsSitemaps]
+	}
+	return sitemaps
+}
+
+// probeCandidates fetches every candidate once, without fallbacks, and scores it
+func (s *Service) probeCandidates(ctx context.Context, candidates []Candidate) {
+	sem := make(chan struct{}, discoveryWorkers)
+	var wg sync.WaitGroup
+
+	for i := range candidates {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(c *Candidate) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			feed := c.Feed
+			feed.Fallbacks = []string{}
+			result := s.TestFeed(ctx, feed)
+
+			if len(result.Attempts) > 0 {
+				c.StatusCode = result.Attempts[0].StatusCode
+			}
+			if result.Err != nil {
+				c.Error = result.Err.Error()
+				return
+			}
+
+			c.Items = len(result.Articles)
+			var newest time.Time
+			for _, a := range result.Articles {
+				if a.PublishedAt.After(newest) {
+					newest = a.PublishedAt
+				}
+			}
+			if !newest.IsZero() {
+				c.Newest = &newest
+				hours := math.Max(time.Since(newest).Hours(), 0)
+				c.Score = math.Log1p(float64(c.Items)) / math.Sqrt(hours+2)
+			}
+		}(&candidates[i])
+	}
+	wg.Wait()
+}
+
+// newCandidateFeed fills a feed with the defaults the admin form would use
+func newCandidateFeed(name, feedURL, kind, host string) models.Feed {
+	country := "int"
+	if i := strings.LastIndex(host, "."); i >= 0 {
+		if c, ok := countryByTLD[host[i+1:]]; ok {
+			country = c
+		}
+	}
+
+	return models.Feed{
+		Name:        name,
+		URL:         feedURL,
+		Type:        kind,
+		Category:    "general",
+		Country:     country,
+		ColorHex:    "#3b82f6",
+		TrustWeight: 1,
+	}
+}
@@ -0,0 +1,158 @@
+package fetcher
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"vidit/internal/database"
+	"vidit/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// fetchLogRetention is how long FeedFetchLog rows are kept
+const fetchLogRetention = 14 * 24 * time.Hour
+
+// StatusError is returned when a source answers with an unexpected HTTP status
+type StatusError struct {
+	Source     string
+	StatusCode int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("%s returned status: %d", e.Source, e.StatusCode)
+}
+
+// FetchAttempt is the outcome of a single strategy for a feed
+type FetchAttempt struct {
+	Strategy    string
+	URL         string
+	StatusCode  int
+	Latency     time.Duration
+	ItemCount   int
+	NotModified bool // 304 to a conditional request: success without new items
+	Err         error
+}
+
+// FetchResult is the outcome of the whole source chain for a feed
+type FetchResult struct {
+	Articles []models.Article
+	Strategy string // Source that produced the articles, empty on failure
+	Target   string // URL or domain that source fetched
+	Attempts []FetchAttempt
+	Cache    HTTPCache // Validators to store for the next conditional request
+	Err      error
+}
+
+// attempt runs one strategy and measures it
+func (s *Service) attempt(strategy, url string, fn func() ([]models.Article, error)) ([]models.Article, FetchAttempt) {
+	start := time.Now()
+	articles, err := fn()
+
+	if errors.Is(err, errNotModified) {
+		return nil, FetchAttempt{
+			Strategy:    strategy,
+			URL:         url,
+			StatusCode:  http.StatusNotModified,
+			Latency:     time.Since(start),
+			NotModified: true,
+		}
+	}
+
+	return articles, FetchAttempt{
+		Strategy:   strategy,
+		URL:        url,
+		StatusCode: statusOf(err),
+		Latency:    time.Since(start),
+		ItemCount:  len(articles),
+		Err:        err,
+	}
+}
+
+// statusOf extracts the HTTP status from a fetch error (200 on success, 0 if unknown)
+func statusOf(err error) int {
+	if err == nil {
+		return http.StatusOK
+	}
+
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode
+	}
+
+	return 0
+}
+
+// recordResult stores the attempts and updates the feed's health counters,
+// disabling it once it reaches maxFailures consecutive failed fetches.
+func (s *Service) recordResult(feed models.Feed, result FetchResult) {
+	logs := make([]models.FeedFetchLog, 0, len(result.Attempts))
+	for _, a := range result.Attempts {
+		entry := models.FeedFetchLog{
+			FeedID:      feed.ID,
+			Strategy:    a.Strategy,
+			URL:         a.URL,
+			StatusCode:  a.StatusCode,
+			LatencyMs:   a.Latency.Milliseconds(),
+			ItemCount:   a.ItemCount,
+			NotModified: a.NotModified,
+			Success:     a.Err == nil && (a.ItemCount > 0 || a.NotModified),
+		}
+		if a.Err != nil {
+			entry.Error = a.Err.Error()
+		}
+		logs = append(logs, entry)
+	}
+
+	if len(logs) > 0 {
+		if err := database.DB.Create(&logs).Error; err != nil {
+			log.Printf("❌ Error saving fetch logs for %s: %v\n", feed.Name, err)
+		}
+	}
+
+	if result.Err == nil {
+		s.markSuccess(feed, result)
+		return
+	}
+
+	s.markFailure(feed, result)
+}
+
+func (s *Service) markFailure(feed models.Feed, result FetchResult) {
+	now := time.Now()
+	failures := feed.ConsecutiveFailures + 1
+
+	updates := scheduleUpdates(feed, result)
+	updates["consecutive_failures"] = gorm.Expr("consecutive_failures + 1")
+	updates["last_error"] = result.Err.Error()
+	updates["last_failure_at"] = &now
+
+	if s.maxFailures > 0 && failures >= s.maxFailures && feed.DisabledAt == nil {
+		updates["disabled_at"] = &now
+		log.Printf("🚫 Disabling feed %s after %d consecutive failures (last error: %v)", feed.Name, failures, result.Err)
+	}
+
+	database.DB.Model(&feed).Updates(updates)
+}
+
+// pruneFetchLogs drops fetch logs older than the retention window
+func (s *Service) pruneFetchLogs(db *gorm.DB) {
+	result := db.Where("created_at < ?", time.Now().Add(-fetchLogRetention)).Delete(&models.FeedFetchLog{})
+	if result.Error != nil {
+		log.Printf("❌ Error pruning fetch logs: %v\n", result.Error)
+	} else if result.RowsAffected > 0 {
+		log.Printf("🧹 Pruned %d old fetch logs\n", result.RowsAffected)
+	}
+}
+
+// EnableFeed clears a feed's failure streak and re-enables it if it was auto-disabled
+func EnableFeed(db *gorm.DB, feed models.Feed) error {
+	return db.Model(&feed).Updates(map[string]interface{}{
+		"consecutive_failures": 0,
+		"disabled_at":          nil,
+		"next_fetch_at":        time.Now(),
+	}).Error
+}
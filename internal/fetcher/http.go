@@ -0,0 +1,84 @@
+package fetcher
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"vidit/internal/models"
+)
+
+// errNotModified is returned when a source answers 304 to a conditional request
+var errNotModified = errors.New("not modified")
+
+// HTTPCache holds the validators of a feed's last successful response
+type HTTPCache struct {
+	ETag         string
+	LastModified string
+}
+
+// get performs a GET request, respecting the per-host concurrency and rate limits.
+// When cache is not nil, the feed's stored validators are sent (If-None-Match /
+// If-Modified-Since) and the new ones are written back into cache.
+// A 304 answer is reported as errNotModified, any other non-200 status as a StatusError.
+func (s *Service) get(ctx context.Context, url, source, userAgent string, feed models.Feed, cache *HTTPCache) (*http.Response, error) {
+	header := make(http.Header)
+	if userAgent != "" {
+		header.Set("User-Agent", userAgent)
+	}
+	return s.getWithHeader(ctx, url, source, header, feed, cache)
+}
+
+// getWithHeader is get with extra request headers, e.g. credentials that must not
+// end up in the URL (and so in errors and fetch logs)
+func (s *Service) getWithHeader(ctx context.Context, url, source string, header http.Header, feed models.Feed, cache *HTTPCache) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	for key, values := range header {
+		req.Header[key] = values
+	}
+
+	if cache != nil {
+		if feed.ETag != "" {
+			req.Header.Set("If-None-Match", feed.ETag)
+		}
+		if feed.LastModified != "" {
+			req.Header.Set("If-Modified-Since", feed.LastModified)
+		}
+	}
+
+	release, err := s.hosts.acquire(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		release()
+		return nil, err
+	}
+	resp.Body = &releaseOnClose{ReadCloser: resp.Body, release: release}
+
+	if resp.StatusCode == http.StatusNotModified {
+		resp.Body.Close()
+		if cache != nil {
+			*cache = HTTPCache{ETag: feed.ETag, LastModified: feed.LastModified}
+		}
+		return nil, errNotModified
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, &StatusError{Source: source, StatusCode: resp.StatusCode}
+	}
+
+	if cache != nil {
+		cache.ETag = resp.Header.Get("ETag")
+		cache.LastModified = resp.Header.Get("Last-Modified")
+	}
+
+	return resp, nil
+}
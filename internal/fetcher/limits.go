@@ -0,0 +1,103 @@
+package fetcher
+
+import (
+	"context"
+	"io"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// Defaults for the fetch cycle, overridable through the environment
+const (
+	DefaultWorkers      = 8               // FETCH_WORKERS: feeds fetched at the same time
+	DefaultPerHost      = 2               // FETCH_PER_HOST: concurrent requests to the same host
+	DefaultHostInterval = 1 * time.Second // FETCH_HOST_INTERVAL: minimum time between requests to the same host
+	DefaultCycleTimeout = 5 * time.Minute // FETCH_CYCLE_TIMEOUT: deadline for a whole fetch cycle
+	DefaultMaxFailures  = 10              // FEED_MAX_FAILURES: consecutive failures before a feed is auto-disabled (0 = never)
+)
+
+// hostLimiter caps concurrency and request rate per host
+type hostLimiter struct {
+	mu       sync.Mutex
+	perHost  int
+	interval time.Duration
+	hosts    map[string]*hostSlot
+}
+
+type hostSlot struct {
+	sem     chan struct{}
+	limiter *rate.Limiter
+}
+
+func newHostLimiter(perHost int, interval time.Duration) *hostLimiter {
+	return &hostLimiter{
+		perHost:  perHost,
+		interval: interval,
+		hosts:    make(map[string]*hostSlot),
+	}
+}
+
+func (hl *hostLimiter) slot(host string) *hostSlot {
+	hl.mu.Lock()
+	defer hl.mu.Unlock()
+
+	slot, ok := hl.hosts[host]
+	if !ok {
+		limit := rate.Inf
+		if hl.interval > 0 {
+			limit = rate.Every(hl.interval)
+		}
+		slot = &hostSlot{
+			sem:     make(chan struct{}, hl.perHost),
+			limiter: rate.NewLimiter(limit, 1),
+		}
+		hl.hosts[host] = slot
+	}
+	return slot
+}
+
+// acquire waits for a free slot and the rate limit of the URL's host.
+// The returned release func must be called once the response is consumed.
+func (hl *hostLimiter) acquire(ctx context.Context, rawURL string) (func(), error) {
+	slot := hl.slot(hostKey(rawURL))
+
+	select {
+	case slot.sem <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	if err := slot.limiter.Wait(ctx); err != nil {
+		<-slot.sem
+		return nil, err
+	}
+
+	var once sync.Once
+	return func() {
+		once.Do(func() { <-slot.sem })
+	}, nil
+}
+
+// releaseOnClose frees the host slot when the response body is closed
+type releaseOnClose struct {
+	io.ReadCloser
+	release func()
+}
+
+func (r *releaseOnClose) Close() error {
+	err := r.ReadCloser.Close()
+	r.release()
+	return err
+}
+
+func hostKey(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	return strings.TrimPrefix(u.Hostname(), "www.")
+}
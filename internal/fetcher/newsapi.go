@@ -1,11 +1,15 @@
 package fetcher
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
+	"net/url"
 	"os"
+	"strings"
 	"time"
 	"vidit/internal/models"
 )
@@ -22,12 +26,13 @@ type NewsAPIResponse struct {
 		Title       string    `json:"title"`
 		Description string    `json:"description"`
 		URL         string    `json:"url"`
+		URLToImage  string    `json:"urlToImage"`
 		PublishedAt time.Time `json:"publishedAt"`
 		Content     string    `json:"content"`
 	} `json:"articles"`
 }
 
-func (s *Service) fetchNewsAPI(feed models.Feed, domainOverride string) ([]models.Article, error) {
+func (s *Service) fetchNewsAPI(ctx context.Context, feed models.Feed, domainOverride string) ([]models.Article, error) {
 	apiKey := os.Getenv("NEWSAPI_KEY")
 	if apiKey == "" {
 		return nil, fmt.Errorf("NEWSAPI_KEY not found in environment")
@@ -38,24 +43,22 @@ func (s *Service) fetchNewsAPI(feed models.Feed, domainOverride string) ([]model
 		targetDomain = domainOverride
 	}
 
-	// We use the Feed URL (or override) as the 'domains' parameter
-	url := fmt.Sprintf("https://newsapi.org/v2/everything?domains=%s&apiKey=%s&pageSize=100&sortBy=publishedAt&language=es", targetDomain, apiKey)
+	// We use the Feed URL (or override) as the 'domains' parameter. The key goes in
+	// a header: a URL is quoted by transport errors, which are stored and shown.
+	endpoint := fmt.Sprintf("https://newsapi.org/v2/everything?domains=%s&pageSize=100&sortBy=publishedAt&language=es", url.QueryEscape(targetDomain))
 	log.Printf("🔍 NewsAPI Request: domains=%s", targetDomain)
 
-	client := http.Client{
-		Timeout: 10 * time.Second,
-	}
-
-	resp, err := client.Get(url)
+	header := http.Header{"X-Api-Key": []string{apiKey}}
+	resp, err := s.getWithHeader(ctx, endpoint, "NewsAPI", header, feed, nil)
 	if err != nil {
+		var statusErr *StatusError
+		if errors.As(err, &statusErr) {
+			return nil, err
+		}
 		return nil, fmt.Errorf("failed to fetch from NewsAPI: %w", err)
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("NewsAPI returned status: %d", resp.StatusCode)
-	}
-
 	var result NewsAPIResponse
 	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
 		return nil, fmt.Errorf("failed to decode NewsAPI response: %w", err)
@@ -79,10 +82,23 @@ func (s *Service) fetchNewsAPI(feed models.Feed, domainOverride string) ([]model
 			continue
 		}
 
+		// Content is cut by NewsAPI ("... [+1234 chars]"), so it's only a fallback
+		summary := plainText(item.Description, SummaryMaxLength)
+		if summary == "" {
+			content := item.Content
+			if i := strings.LastIndex(content, "[+"); i > 0 {
+				content = content[:i]
+			}
+			summary = plainText(content, SummaryMaxLength)
+		}
+
 		articles = append(articles, models.Article{
 			Title:       item.Title,
 			URL:         item.URL,
 			PublishedAt: item.PublishedAt,
+			Summary:     summary,
+			Author:      plainText(item.Author, 200),
+			ImageURL:    safeURL(item.URLToImage, item.URL),
 			FeedID:      feed.ID,
 			Score:       1, // Default score, will be recalculated
 		})
@@ -1,53 +1,79 @@
 package fetcher
 
 import (
+	"fmt"
 	"math"
 	"sort"
 	"strings"
 	"time"
+	"vidit/internal/minhash"
 	"vidit/internal/models"
+	"vidit/internal/textproc"
 )
 
 // RankingService handles the sorting, scoring, and deduplication of news articles.
-type RankingService struct{}
+// Its parameters come from Config, or from the shared ranking configuration when nil.
+// Ages are measured up to Now, or up to the current time when zero.
+type RankingService struct {
+	Config *models.RankingConfig
+	Now    time.Time
+}
 
-// Constants requested by the user
-const (
-	WeightRSS     = 1.5
-	WeightSitemap = 1.2
-	WeightAPI     = 1.0
+func (rs *RankingService) config() models.RankingConfig {
+	if rs.Config != nil {
+		return *rs.Config
+	}
+	return CurrentRankingConfig()
+}
 
-	WeightChile   = 1.0 // Boost for Chilean sources
-	WeightCluster = 2.5
-	GravityDecay  = 1.8
+func (rs *RankingService) now() time.Time {
+	if rs.Now.IsZero() {
+		return time.Now()
+	}
+	return rs.Now
+}
 
-	ThresholdCluster = 0.4
-	ThresholdDedup   = 0.35
-)
+// Rank scores every article by gravity and sorts them (descending) without dropping any
+func (rs *RankingService) Rank(articles []models.Article) []models.Article {
+	return rs.RankWithContext(articles, nil)
+}
 
-// RankAndDedup orchestrates the entire ranking process
-func (rs *RankingService) RankAndDedup(articles []models.Article) []models.Article {
+// RankWithContext is like Rank, but cluster counts also include similar articles
+// from context (e.g. already stored ones), which are not scored themselves
+func (rs *RankingService) RankWithContext(articles, context []models.Article) []models.Article {
 	if len(articles) == 0 {
 		return articles
 	}
 
+	cfg := rs.config()
+
 	// 1. Cluster Analysis & Network Building
-	// We need to know how many OTHER articles talk about the same topic (ClusterCount)
+	// We need to know how many OTHER articles talk about the same topic (ClusterCount).
+	// Candidates come from a MinHash LSH index, so only likely pairs are compared.
 	clusterCounts := make([]int, len(articles))
 
-	for i := 0; i < len(articles); i++ {
-		for j := i + 1; j < len(articles); j++ {
-			similarity := rs.jaccardSimilarity(articles[i].Title, articles[j].Title)
-			if similarity > ThresholdCluster {
+	tokens := rs.tokenizeAll(articles)
+	all := append(tokens[:len(tokens):len(tokens)], rs.tokenizeAll(context)...)
+
+	idx := minhash.NewIndex(minhash.DefaultBands, minhash.DefaultRows)
+	sigs := make([]minhash.Signature, len(all))
+	for i, t := range all {
+		sigs[i] = idx.Sign(t)
+		idx.Insert(sigs[i])
+	}
+
+	for i := range articles {
+		for _, j := range idx.Candidates(sigs[i]) {
+			if j != i && rs.Similarity(tokens[i], all[j]) > cfg.ThresholdCluster {
 				clusterCounts[i]++
-				clusterCounts[j]++
 			}
 		}
 	}
 
 	// 2. Score Calculation
 	for i := range articles {
-		articles[i].Score = rs.calculateGravity(articles[i], clusterCounts[i])
+		articles[i].ClusterCount = clusterCounts[i]
+		articles[i].Score = rs.gravity(cfg, articles[i], clusterCounts[i])
 	}
 
 	// 3. Sort by Score (Descending)
@@ -55,21 +81,64 @@ func (rs *RankingService) RankAndDedup(articles []models.Article) []models.Artic
 		return articles[i].Score > articles[j].Score
 	})
 
-	// 4. Deduplication
+	return articles
+}
+
+// ContextMatches counts, for each context article, how many of the given articles are
+// similar to it. Used to grow the stored cluster counts when new articles arrive.
+func (rs *RankingService) ContextMatches(articles, context []models.Article) map[uint]int {
+	matches := make(map[uint]int)
+	if len(articles) == 0 {
+		return matches
+	}
+
+	cfg := rs.config()
+
+	contextTokens := rs.tokenizeAll(context)
+	idx := minhash.NewIndex(minhash.DefaultBands, minhash.DefaultRows)
+	for _, t := range contextTokens {
+		idx.Add(t)
+	}
+
+	for _, a := range articles {
+		tokens := rs.Tokenize(a.Title)
+		for _, j := range idx.Query(tokens) {
+			if rs.Similarity(tokens, contextTokens[j]) > cfg.ThresholdCluster {
+				matches[context[j].ID]++
+			}
+		}
+	}
+
+	return matches
+}
+
+// RankAndDedup ranks the articles and keeps only the *best* version of each story.
+// The fetcher no longer uses it (see saveStories), but it is handy for one-off analysis.
+func (rs *RankingService) RankAndDedup(articles []models.Article) []models.Article {
+	articles = rs.Rank(articles)
+	threshold := rs.config().ThresholdDedup
+
 	// Iterate through the sorted list and keep only the *best* version of each story.
+	// Each candidate is only compared with the kept articles the LSH index suggests.
 	var finalArticles []models.Article
+	var keptTokens []map[string]bool
+	idx := minhash.NewIndex(minhash.DefaultBands, minhash.DefaultRows)
 
 	for _, candidate := range articles {
+		tokens := rs.Tokenize(candidate.Title)
+		sig := idx.Sign(tokens)
+
 		isDuplicate := false
-		for _, kept := range finalArticles {
-			similarity := rs.jaccardSimilarity(candidate.Title, kept.Title)
-			if similarity > ThresholdDedup {
+		for _, k := range idx.Candidates(sig) {
+			if rs.Similarity(tokens, keptTokens[k]) > threshold {
 				isDuplicate = true
 				break
 			}
 		}
 
 		if !isDuplicate {
+			idx.Insert(sig)
+			keptTokens = append(keptTokens, tokens)
 			finalArticles = append(finalArticles, candidate)
 		}
 	}
@@ -77,37 +146,79 @@ func (rs *RankingService) RankAndDedup(articles []models.Article) []models.Artic
 	return finalArticles
 }
 
-func (rs *RankingService) calculateGravity(article models.Article, clusterCount int) float64 {
-	// Formula: Score = (PesoFuente + BoostChile + (ConteoCluster * PesoCluster)) / (HorasTranscurridas + 2)^Gravedad
+// Gravity scores a single article given how many similar articles it has
+func (rs *RankingService) Gravity(article models.Article, clusterCount int) float64 {
+	return rs.gravity(rs.config(), article, clusterCount)
+}
+
+func (rs *RankingService) gravity(cfg models.RankingConfig, article models.Article, clusterCount int) float64 {
+	return rs.breakdown(cfg, article, clusterCount).Score
+}
+
+// ScoreBreakdown shows every term of the gravity formula for one article
+type ScoreBreakdown struct {
+	TypeWeight    float64 // By feed type (rss, sitemap, newsapi)
+	TrustWeight   float64 // Per feed
+	CountryBoost  float64
+	CategoryBoost float64
+	ClusterCount  int
+	ClusterWeight float64
+	HoursElapsed  float64
+	GravityDecay  float64
+	Score         float64
+}
+
+// Breakdown explains the article's score using its stored cluster count
+func (rs *RankingService) Breakdown(article models.Article) ScoreBreakdown {
+	return rs.breakdown(rs.config(), article, article.ClusterCount)
+}
+
+func (rs *RankingService) breakdown(cfg models.RankingConfig, article models.Article, clusterCount int) ScoreBreakdown {
+	// Formula: Score = (PesoTipo * ConfianzaFuente + BoostPaís + BoostCategoría + (ConteoCluster * PesoCluster)) / (HorasTranscurridas + 2)^Gravedad
+
+	b := ScoreBreakdown{
+		TypeWeight:    cfg.WeightRSS,
+		TrustWeight:   article.Feed.TrustWeight,
+		CountryBoost:  cfg.CountryBoosts[strings.ToUpper(article.Feed.Country)],
+		CategoryBoost: cfg.CategoryBoosts[strings.ToLower(article.Feed.Category)],
+		ClusterCount:  clusterCount,
+		ClusterWeight: cfg.WeightCluster,
+		GravityDecay:  cfg.GravityDecay,
+	}
 
-	sourceWeight := WeightRSS
 	switch article.Feed.Type {
 	case "sitemap":
-		sourceWeight = WeightSitemap
+		b.TypeWeight = cfg.WeightSitemap
 	case "newsapi":
-		sourceWeight = WeightAPI
+		b.TypeWeight = cfg.WeightAPI
 	}
 
-	// Boost Chilean sources
-	if article.Feed.Country == "CL" {
-		sourceWeight += WeightChile
+	// Unloaded feeds count as neutral; a stored 0 mutes the source
+	if article.Feed.ID == 0 || b.TrustWeight < 0 {
+		b.TrustWeight = 1
 	}
 
-	hoursElapsed := time.Since(article.PublishedAt).Hours()
-	if hoursElapsed < 0 {
-		hoursElapsed = 0
+	b.HoursElapsed = rs.now().Sub(article.PublishedAt).Hours()
+	if b.HoursElapsed < 0 {
+		b.HoursElapsed = 0
 	}
 
-	numerator := sourceWeight + (float64(clusterCount) * WeightCluster)
-	denominator := math.Pow(hoursElapsed+2, GravityDecay)
+	numerator := b.TypeWeight*b.TrustWeight + b.CountryBoost + b.CategoryBoost + (float64(clusterCount) * b.ClusterWeight)
+	denominator := math.Pow(b.HoursElapsed+2, b.GravityDecay)
 
-	return numerator / denominator
+	b.Score = numerator / denominator
+	return b
 }
 
-func (rs *RankingService) jaccardSimilarity(s1, s2 string) float64 {
-	set1 := rs.tokenize(s1)
-	set2 := rs.tokenize(s2)
+// String renders the breakdown for the card's score tooltip
+func (b ScoreBreakdown) String() string {
+	return fmt.Sprintf("Tipo %.2f × confianza %.2f + país %.2f + categoría %.2f + %d similares × %.2f\n÷ (%.1fh + 2)^%.2f = %.3f",
+		b.TypeWeight, b.TrustWeight, b.CountryBoost, b.CategoryBoost, b.ClusterCount, b.ClusterWeight,
+		b.HoursElapsed, b.GravityDecay, b.Score)
+}
 
+// Similarity is the Jaccard index of two token sets
+func (rs *RankingService) Similarity(set1, set2 map[string]bool) float64 {
 	if len(set1) == 0 || len(set2) == 0 {
 		return 0.0
 	}
@@ -123,17 +234,16 @@ func (rs *RankingService) jaccardSimilarity(s1, s2 string) float64 {
 	return float64(intersection) / float64(union)
 }
 
-func (rs *RankingService) tokenize(text string) map[string]bool {
-	tokens := make(map[string]bool)
-	words := strings.Fields(strings.ToLower(text))
-	for _, w := range words {
-		// Basic cleanup: remove punctuation
-		w = strings.TrimFunc(w, func(r rune) bool {
-			return !((r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r > 127) // Keep latin supplement
-		})
-		if len(w) > 2 {
-			tokens[w] = true
-		}
+func (rs *RankingService) tokenizeAll(articles []models.Article) []map[string]bool {
+	tokens := make([]map[string]bool, len(articles))
+	for i, a := range articles {
+		tokens[i] = rs.Tokenize(a.Title)
 	}
 	return tokens
 }
+
+// Tokenize returns the set of significant words of a title: accent-folded,
+// without stopwords and stemmed (see textproc)
+func (rs *RankingService) Tokenize(text string) map[string]bool {
+	return textproc.TokenSet(text)
+}
@@ -0,0 +1,155 @@
+package fetcher
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"vidit/internal/env"
+	"vidit/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// Defaults for the background rescoring job, overridable through the environment
+const (
+	DefaultRescoreInterval = 10 * time.Minute // RESCORE_INTERVAL: how often stored scores are decayed
+	DefaultRescoreWindow   = 72 * time.Hour   // RESCORE_WINDOW: articles older than this keep their last score
+	rescoreBatchSize       = 500
+)
+
+// Rescorer keeps articles.score time-decayed between fetch cycles.
+// It reuses the stored cluster counts, so only the gravity formula is re-applied.
+type Rescorer struct {
+	db       *gorm.DB
+	ranking  *RankingService
+	interval time.Duration
+	window   time.Duration
+}
+
+func NewRescorer(db *gorm.DB) *Rescorer {
+	return &Rescorer{
+		db:       db,
+		ranking:  &RankingService{},
+		interval: env.Duration("RESCORE_INTERVAL", DefaultRescoreInterval, 0),
+		window:   env.Duration("RESCORE_WINDOW", DefaultRescoreWindow, 0),
+	}
+}
+
+// Run rescores the live window every interval until the context is cancelled
+func (r *Rescorer) Run(ctx context.Context) {
+	if r.interval <= 0 {
+		log.Println("⚠️  Rescoring disabled (RESCORE_INTERVAL=0)")
+		return
+	}
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if err := r.Rescore(ctx); err != nil {
+			log.Printf("❌ Rescoring failed: %v\n", err)
+		}
+	}
+}
+
+// Rescore recomputes the score of every article published inside the window,
+// in batches by ID, and then refreshes the stories they belong to.
+func (r *Rescorer) Rescore(ctx context.Context) error {
+	start := time.Now()
+	since := start.Add(-r.window)
+
+	var lastID uint
+	total := 0
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		var batch []models.Article
+		err := r.db.Preload("Feed").
+			Select("id", "published_at", "cluster_count", "feed_id").
+			Where("published_at > ? AND id > ?", since, lastID).
+			Order("id ASC").
+			Limit(rescoreBatchSize).
+			Find(&batch).Error
+		if err != nil {
+			return err
+		}
+		if len(batch) == 0 {
+			break
+		}
+
+		cfg := r.ranking.config()
+		for i := range batch {
+			batch[i].Score = r.ranking.gravity(cfg, batch[i], batch[i].ClusterCount)
+		}
+		if err := updateScores(r.db, batch); err != nil {
+			return err
+		}
+
+		total += len(batch)
+		lastID = batch[len(batch)-1].ID
+	}
+
+	var storyIDs []uint
+	err := r.db.Model(&models.Story{}).
+		Where("last_seen_at > ?", since).
+		Pluck("id", &storyIDs).Error
+	if err != nil {
+		return err
+	}
+	if err := RefreshStories(r.db, storyIDs); err != nil {
+		return err
+	}
+
+	log.Printf("♻️  Rescored %d articles and %d stories in %v\n", total, len(storyIDs), time.Since(start).Round(time.Millisecond))
+	return nil
+}
+
+// updateScores writes the articles' scores with a single UPDATE ... FROM (VALUES ...)
+func updateScores(db *gorm.DB, articles []models.Article) error {
+	if len(articles) == 0 {
+		return nil
+	}
+
+	values := make([]string, 0, len(articles))
+	args := make([]interface{}, 0, len(articles)*2)
+	for _, a := range articles {
+		values = append(values, "(?::bigint, ?::double precision)")
+		args = append(args, a.ID, a.Score)
+	}
+
+	query := fmt.Sprintf(`
+		UPDATE articles SET score = v.score
+		FROM (VALUES %s) AS v(id, score)
+		WHERE articles.id = v.id`, strings.Join(values, ", "))
+
+	return db.Exec(query, args...).Error
+}
+
+// bumpClusterCounts adds the given number of new similar articles to each stored article
+func bumpClusterCounts(db *gorm.DB, matches map[uint]int) error {
+	byCount := make(map[int][]uint)
+	for id, n := range matches {
+		byCount[n] = append(byCount[n], id)
+	}
+
+	for n, ids := range byCount {
+		err := db.Model(&models.Article{}).
+			Where("id IN ?", ids).
+			UpdateColumn("cluster_count", gorm.Expr("cluster_count + ?", n)).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
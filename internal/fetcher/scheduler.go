@@ -0,0 +1,187 @@
+package fetcher
+
+import (
+	"context"
+	"log"
+	"math/rand"
+	"sort"
+	"time"
+
+	"vidit/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// Polling bounds for the adaptive scheduler
+const (
+	DefaultPollInterval = 15 * time.Minute
+	MinPollInterval     = 5 * time.Minute // Wire services
+	MaxPollInterval     = 24 * time.Hour  // Weekly blogs
+	SchedulerTick       = 1 * time.Minute // How often due feeds are looked up
+	maxBackoffSteps     = 6               // Failing feeds wait up to interval * 2^6 (capped at MaxPollInterval)
+	publishSampleSize   = 20              // Most recent items used to estimate the publishing rate
+)
+
+// Scheduler polls each feed on its own schedule (feeds.next_fetch_at) instead of one global ticker
+type Scheduler struct {
+	db      *gorm.DB
+	service *Service
+
+	// AfterFetch, when set, runs after every round of due feeds (e.g. to publish new stories)
+	AfterFetch func(ctx context.Context)
+}
+
+func NewScheduler(db *gorm.DB, service *Service) *Scheduler {
+	return &Scheduler{
+		db:      db,
+		service: service,
+	}
+}
+
+// Run fetches due feeds every SchedulerTick until the context is cancelled
+func (sc *Scheduler) Run(ctx context.Context) {
+	sc.seed()
+
+	ticker := time.NewTicker(SchedulerTick)
+	defer ticker.Stop()
+
+	for {
+		if err := sc.service.FetchDueFeeds(ctx, sc.db); err != nil {
+			log.Printf("❌ Scheduled fetch failed: %v\n", err)
+		}
+		if sc.AfterFetch != nil {
+			sc.AfterFetch(ctx)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// seed spreads never-scheduled feeds over their first interval so a fresh
+// database (or a batch of new feeds) doesn't fetch everything at once
+func (sc *Scheduler) seed() {
+	var feeds []models.Feed
+	if err := sc.db.Where("next_fetch_at IS NULL AND disabled_at IS NULL").Find(&feeds).Error; err != nil {
+		log.Printf("❌ Error loading unscheduled feeds: %v\n", err)
+		return
+	}
+
+	now := time.Now()
+	for _, feed := range feeds {
+		interval := feedInterval(feed)
+		next := now.Add(time.Duration(rand.Int63n(int64(interval))))
+		sc.db.Model(&feed).Update("next_fetch_at", next)
+	}
+
+	if len(feeds) > 0 {
+		log.Printf("🗓️  Scheduled %d new feeds\n", len(feeds))
+	}
+}
+
+// FetchDueFeeds fetches the enabled feeds whose next_fetch_at has passed
+func (s *Service) FetchDueFeeds(ctx context.Context, db *gorm.DB) error {
+	var feeds []models.Feed
+	err := db.Where("disabled_at IS NULL AND (next_fetch_at IS NULL OR next_fetch_at <= ?)", time.Now()).
+		Order("next_fetch_at ASC").
+		Find(&feeds).Error
+	if err != nil {
+		return err
+	}
+
+	if len(feeds) == 0 {
+		return nil
+	}
+
+	return s.FetchFeeds(ctx, db, feeds)
+}
+
+// feedInterval is the feed's current polling interval
+func feedInterval(feed models.Feed) time.Duration {
+	if feed.FetchIntervalMinutes <= 0 {
+		return DefaultPollInterval
+	}
+	return clampInterval(time.Duration(feed.FetchIntervalMinutes) * time.Minute)
+}
+
+// pollInterval estimates how often a feed should be polled from the gaps between
+// its most recent items: half the median gap, smoothed with the previous interval.
+func pollInterval(feed models.Feed, articles []models.Article) time.Duration {
+	current := feedInterval(feed)
+
+	times := make([]time.Time, 0, len(articles))
+	for _, a := range articles {
+		times = append(times, a.PublishedAt)
+	}
+	sort.Slice(times, func(i, j int) bool {
+		return times[i].After(times[j])
+	})
+	if len(times) > publishSampleSize {
+		times = times[:publishSampleSize]
+	}
+
+	var gaps []time.Duration
+	for i := 1; i < len(times); i++ {
+		// Items without a date all get time.Now() and say nothing about the rate
+		if gap := times[i-1].Sub(times[i]); gap > 0 {
+			gaps = append(gaps, gap)
+		}
+	}
+
+	if len(gaps) == 0 {
+		return current
+	}
+
+	sort.Slice(gaps, func(i, j int) bool { return gaps[i] < gaps[j] })
+	ideal := gaps[len(gaps)/2] / 2
+
+	return clampInterval((current + ideal) / 2)
+}
+
+// nextFetchAt applies exponential backoff for failing feeds plus up to 10% jitter
+func nextFetchAt(interval time.Duration, failures int) time.Time {
+	wait := interval
+	if failures > 0 {
+		steps := failures
+		if steps > maxBackoffSteps {
+			steps = maxBackoffSteps
+		}
+		wait = interval << steps
+		if wait > MaxPollInterval {
+			wait = MaxPollInterval
+		}
+	}
+
+	jitter := time.Duration(rand.Int63n(int64(wait/10) + 1))
+	return time.Now().Add(wait + jitter)
+}
+
+func clampInterval(d time.Duration) time.Duration {
+	if d < MinPollInterval {
+		return MinPollInterval
+	}
+	if d > MaxPollInterval {
+		return MaxPollInterval
+	}
+	return d
+}
+
+// scheduleUpdates returns the columns to persist the feed's next poll
+func scheduleUpdates(feed models.Feed, result FetchResult) map[string]interface{} {
+	interval := feedInterval(feed)
+	failures := 0
+
+	if result.Err == nil {
+		interval = pollInterval(feed, result.Articles)
+	} else {
+		failures = feed.ConsecutiveFailures + 1
+	}
+
+	return map[string]interface{}{
+		"fetch_interval_minutes": int(interval / time.Minute),
+		"next_fetch_at":          nextFetchAt(interval, failures),
+	}
+}
@@ -0,0 +1,227 @@
+package fetcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"vidit/internal/database"
+	"vidit/internal/models"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/andybalholm/cascadia"
+	"golang.org/x/net/html/charset"
+)
+
+// maxScrapedItems caps the headlines taken from a single page
+const maxScrapedItems = 100
+
+// Browser-like agent: some front pages refuse unknown clients
+const scrapeUserAgent = "Mozilla/5.0 (compatible; ViditBot/1.0)"
+
+// spanishMonths lets text dates like "18 de octubre de 2026" be parsed with
+// English layouts ("2 de January de 2006")
+var spanishMonths = strings.NewReplacer(
+	"enero", "January", "febrero", "February", "marzo", "March", "abril", "April",
+	"mayo", "May", "junio", "June", "julio", "July", "agosto", "August",
+	"septiembre", "September", "setiembre", "September", "octubre", "October",
+	"noviembre", "November", "diciembre", "December",
+)
+
+type scrapeSource struct{ s *Service }
+
+func newScrapeSource(s *Service) Source { return scrapeSource{s} }
+
+// Target is only the feed's own page: selectors are written for it
+func (src scrapeSource) Target(feed models.Feed, primary bool) string {
+	if !primary || feed.ScrapeRules == nil {
+		return ""
+	}
+	return feed.URL
+}
+
+func (src scrapeSource) Fetch(ctx context.Context, feed models.Feed, target string, cache *HTTPCache) ([]models.Article, error) {
+	resp, err := src.s.get(ctx, target, "scrape", scrapeUserAgent, feed, cache)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	scraped, err := ScrapeArticles(resp.Body, resp.Header.Get("Content-Type"), target, *feed.ScrapeRules)
+	if err != nil {
+		return nil, err
+	}
+
+	stored := storedDates(scraped)
+
+	articles := make([]models.Article, 0, len(scraped))
+	for _, a := range scraped {
+		if src.s.isGossip(a.Title, nil) {
+			continue
+		}
+		// Undated headlines keep the time they were first seen, so they don't look new on every fetch
+		if a.PublishedAt.IsZero() {
+			a.PublishedAt = time.Now()
+			if t, ok := stored[a.URL]; ok {
+				a.PublishedAt = t
+			}
+		}
+		a.FeedID = feed.ID
+		articles = append(articles, a)
+	}
+	return articles, nil
+}
+
+// storedDates returns the publication date already stored for the scraped URLs
+func storedDates(articles []models.Article) map[string]time.Time {
+	dates := make(map[string]time.Time)
+	if database.DB == nil {
+		return dates
+	}
+
+	var urls []string
+	for _, a := range articles {
+		if a.PublishedAt.IsZero() {
+			urls = append(urls, a.URL)
+		}
+	}
+	if len(urls) == 0 {
+		return dates
+	}
+
+	var rows []models.Article
+	database.DB.Select("url", "published_at").Where("url IN ?", urls).Find(&rows)
+	for _, r := range rows {
+		dates[r.URL] = r.PublishedAt
+	}
+	return dates
+}
+
+// ValidateScrapeRules checks that the rules have an item and a title and that
+// every selector is valid CSS
+func ValidateScrapeRules(rules *models.ScrapeRules) error {
+	if rules == nil || strings.TrimSpace(rules.Item) == "" || strings.TrimSpace(rules.Title) == "" {
+		return errors.New("scrape rules need item and title selectors")
+	}
+
+	selectors := map[string]string{
+		"item": rules.Item, "title": rules.Title, "link": rules.Link,
+		"date": rules.Date, "summary": rules.Summary, "image": rules.Image,
+	}
+	for name, sel := range selectors {
+		if sel == "" {
+			continue
+		}
+		if _, err := cascadia.ParseGroup(sel); err != nil {
+			return fmt.Errorf("invalid %s selector %q: %v", name, sel, err)
+		}
+	}
+
+	if rules.Timezone != "" {
+		if _, err := time.LoadLocation(rules.Timezone); err != nil {
+			return fmt.Errorf("unknown timezone %q", rules.Timezone)
+		}
+	}
+	return nil
+}
+
+// ScrapeArticles extracts the headlines of an HTML page with the given rules.
+// contentType is used to decode non-UTF-8 pages; links are resolved against pageURL.
+// Articles without a readable date have a zero PublishedAt and no FeedID.
+func ScrapeArticles(body io.Reader, contentType, pageURL string, rules models.ScrapeRules) ([]models.Article, error) {
+	if err := ValidateScrapeRules(&rules); err != nil {
+		return nil, err
+	}
+
+	utf8Body, err := charset.NewReader(body, contentType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode page: %w", err)
+	}
+	doc, err := goquery.NewDocumentFromReader(utf8Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse HTML: %w", err)
+	}
+
+	loc := time.UTC
+	if rules.Timezone != "" {
+		loc, _ = time.LoadLocation(rules.Timezone)
+	}
+
+	var articles []models.Article
+	seen := make(map[string]bool)
+	doc.Find(rules.Item).EachWithBreak(func(_ int, item *goquery.Selection) bool {
+		titleSel := item.Find(rules.Title).First()
+		title := collapseSpaces(strings.TrimSpace(titleSel.Text()))
+
+		var linkSel *goquery.Selection
+		if rules.Link != "" {
+			linkSel = item.Find(rules.Link).First()
+		} else {
+			// The title is usually inside the link, or the link inside the title
+			linkSel = titleSel.Closest("a")
+			if linkSel.Length() == 0 {
+				linkSel = titleSel.Find("a").First()
+			}
+		}
+		link := safeURL(linkSel.AttrOr("href", ""), pageURL)
+
+		if title == "" || link == "" || link == pageURL || seen[link] {
+			return true
+		}
+		seen[link] = true
+
+		article := models.Article{
+			Title: title,
+			URL:   link,
+			Score: 1,
+		}
+		if rules.Date != "" {
+			article.PublishedAt = scrapedDate(item.Find(rules.Date).First(), rules.DateFormat, loc)
+		}
+		if rules.Summary != "" {
+			article.Summary = truncate(collapseSpaces(strings.TrimSpace(item.Find(rules.Summary).First().Text())), SummaryMaxLength)
+		}
+		if rules.Image != "" {
+			img := item.Find(rules.Image).First()
+			for _, attr := range []string{"src", "data-src", "content"} {
+				if article.ImageURL = safeURL(img.AttrOr(attr, ""), link); article.ImageURL != "" {
+					break
+				}
+			}
+		}
+
+		articles = append(articles, article)
+		return len(articles) < maxScrapedItems
+	})
+
+	return articles, nil
+}
+
+// scrapedDate reads the date of an item from machine-readable attributes first,
+// then from its text with the rule's layout or the usual publisher formats
+func scrapedDate(sel *goquery.Selection, layout string, loc *time.Location) time.Time {
+	for _, attr := range []string{"datetime", "content", "data-date"} {
+		if v, ok := sel.Attr(attr); ok {
+			if t, ok := parsePublisherDateIn(v, loc); ok {
+				return t
+			}
+		}
+	}
+
+	text := collapseSpaces(strings.TrimSpace(sel.Text()))
+	if text == "" {
+		return time.Time{}
+	}
+	if layout != "" {
+		if t, err := time.ParseInLocation(layout, spanishMonths.Replace(strings.ToLower(text)), loc); err == nil {
+			return t
+		}
+	}
+	if t, ok := parsePublisherDateIn(text, loc); ok {
+		return t
+	}
+	return time.Time{}
+}
@@ -1,13 +1,18 @@
 package fetcher
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"log"
+	"net/http"
 	"net/url"
 	"strings"
 	"sync"
 	"time"
 
 	"vidit/internal/database"
+	"vidit/internal/env"
 	"vidit/internal/models"
 
 	"github.com/mmcdole/gofeed"
@@ -16,13 +21,38 @@ import (
 )
 
 type Service struct {
-	parser *gofeed.Parser
+	parser       *gofeed.Parser
+	client       *http.Client
+	hosts        *hostLimiter
+	workers      int
+	cycleTimeout time.Duration
+	maxFailures  int // Consecutive failures before a feed is auto-disabled (0 = never)
+	sources      map[string]Source
+	keepGossip   bool // Set by KeepGossip, for tools that measure the filter
 }
 
 func NewService() *Service {
-	return &Service{
+	s := &Service{
 		parser: gofeed.NewParser(),
+		client: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+		hosts:        newHostLimiter(env.Int("FETCH_PER_HOST", DefaultPerHost, 1), env.Duration("FETCH_HOST_INTERVAL", DefaultHostInterval, 0)),
+		workers:      env.Int("FETCH_WORKERS", DefaultWorkers, 1),
+		cycleTimeout: env.Duration("FETCH_CYCLE_TIMEOUT", DefaultCycleTimeout, 0),
+		maxFailures:  env.Int("FEED_MAX_FAILURES", DefaultMaxFailures, 0),
+		sources:      make(map[string]Source, len(sourceRegistry)),
 	}
+	for name, entry := range sourceRegistry {
+		s.sources[name] = entry.factory(s)
+	}
+	return s
+}
+
+// KeepGossip disables the gossip filter, so fetched items can be checked with IsGossip
+func (s *Service) KeepGossip() *Service {
+	s.keepGossip = true
+	return s
 }
 
 type FeedItem struct {
@@ -32,9 +62,10 @@ type FeedItem struct {
 	FeedID      uint
 }
 
-func (s *Service) FetchAllFeeds(db *gorm.DB) error {
+// FetchAllFeeds fetches every enabled feed right away, ignoring their schedule
+func (s *Service) FetchAllFeeds(ctx context.Context, db *gorm.DB) error {
 	var feeds []models.Feed
-	if err := db.Find(&feeds).Error; err != nil {
+	if err := db.Where("disabled_at IS NULL").Find(&feeds).Error; err != nil {
 		return err
 	}
 
@@ -43,20 +74,51 @@ func (s *Service) FetchAllFeeds(db *gorm.DB) error {
 		return nil
 	}
 
-	log.Printf("🔄 Fetching %d feeds concurrently...\n", len(feeds))
+	return s.FetchFeeds(ctx, db, feeds)
+}
 
-	var wg sync.WaitGroup
+// FetchFeeds fetches the given feeds with a bounded worker pool, ranks the new items
+// against the recent archive and saves them into stories. The whole cycle is bounded
+// by cycleTimeout; whatever was fetched before the deadline is still saved.
+func (s *Service) FetchFeeds(ctx context.Context, db *gorm.DB, feeds []models.Feed) error {
+	defer s.pruneFetchLogs(db)
+
+	cycleCtx, cancel := context.WithTimeout(ctx, s.cycleTimeout)
+	defer cancel()
+
+	workers := s.workers
+	if workers > len(feeds) {
+		workers = len(feeds)
+	}
+
+	log.Printf("🔄 Fetching %d feeds with %d workers...\n", len(feeds), workers)
+
+	jobs := make(chan models.Feed)
 	itemsChan := make(chan []models.Article, len(feeds))
 
-	for _, feed := range feeds {
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
 		wg.Add(1)
-		go func(f models.Feed) {
+		go func() {
 			defer wg.Done()
-			articles := s.FetchFeed(f)
-			itemsChan <- articles
-		}(feed)
+			for f := range jobs {
+				itemsChan <- s.FetchFeed(cycleCtx, f)
+			}
+		}()
 	}
 
+	go func() {
+		defer close(jobs)
+		for _, feed := range feeds {
+			select {
+			case jobs <- feed:
+			case <-cycleCtx.Done():
+				log.Printf("⏱️  Fetch cycle stopped: %v\n", cycleCtx.Err())
+				return
+			}
+		}
+	}()
+
 	go func() {
 		wg.Wait()
 		close(itemsChan)
@@ -81,107 +143,183 @@ func (s *Service) FetchAllFeeds(db *gorm.DB) error {
 
 	log.Printf("🔹 processed %d unique articles from raw list\n", len(uniqueArticles))
 
-	// RANKING & DEDUPLICATION
+	if len(uniqueArticles) == 0 {
+		return nil
+	}
+
+	// RANKING & STORY CLUSTERING
+	// Nothing is dropped: near-duplicates are grouped into the same story instead.
+	// Only a few feeds are due at a time, so clusters are also counted against recent articles.
+	recent, err := s.recentArticles(db, uniqueArticlesMap)
+	if err != nil {
+		return err
+	}
+
 	rs := &RankingService{}
-	finalArticles := rs.RankAndDedup(uniqueArticles)
+	rankedArticles := rs.RankWithContext(uniqueArticles, recent)
 
-	log.Printf("✨ Gravity Ranking complete. Reduced to %d articles.\n", len(finalArticles))
+	log.Printf("✨ Gravity Ranking complete for %d articles.\n", len(rankedArticles))
 
-	if len(finalArticles) > 0 {
-		return s.saveArticles(db, finalArticles)
+	if len(rankedArticles) == 0 {
+		return nil
 	}
 
-	return nil
-}
+	// Stored articles similar to brand-new ones gain cluster members as well
+	existing, err := s.articleIDsByURL(db, rankedArticles)
+	if err != nil {
+		return err
+	}
+	var fresh []models.Article
+	for _, a := range rankedArticles {
+		if _, ok := existing[a.URL]; !ok {
+			fresh = append(fresh, a)
+		}
+	}
+
+	if err := s.saveArticles(db, rankedArticles); err != nil {
+		return err
+	}
+
+	if err := bumpClusterCounts(db, rs.ContextMatches(fresh, recent)); err != nil {
+		log.Printf("❌ Error updating cluster counts: %v\n", err)
+	}
 
-func (s *Service) FetchFeed(feed models.Feed) []models.Article {
-	var articles []models.Article
-	var err error
+	return s.saveStories(db, rs, rankedArticles)
+}
 
-	// STRATEGY: Waterfall (RSS -> NewsAPI -> Sitemap)
-	// We determine the "Starting Point" based on feed.Type, but if it fails, we cascade down.
+// recentArticles loads the titles published inside the story window, minus the ones being ranked
+func (s *Service) recentArticles(db *gorm.DB, exclude map[string]models.Article) ([]models.Article, error) {
+	var recent []models.Article
+	err := db.Select("id", "title", "url").
+		Where("published_at > ?", time.Now().Add(-StoryWindow)).
+		Find(&recent).Error
+	if err != nil {
+		return nil, err
+	}
 
-	// 1. Attempt RSS (default or explicitly rss)
-	if feed.Type == "rss" || feed.Type == "" {
-		articles, err = s.fetchRSS(feed)
-		if len(articles) > 0 {
-			s.markSuccess(feed, "rss")
-			return articles
+	filtered := recent[:0]
+	for _, a := range recent {
+		if _, ok := exclude[a.URL]; !ok {
+			filtered = append(filtered, a)
 		}
-		log.Printf("⚠️  RSS failed for %s (%s). Trying NewsAPI fallback...", feed.Name, feed.URL)
 	}
+	return filtered, nil
+}
 
-	// 2. Attempt NewsAPI (explicitly newsapi OR fallback)
-	canUseNewsAPI := feed.Type == "newsapi" || feed.Type == "rss" || feed.Type == ""
-	if canUseNewsAPI {
-		domain := s.extractDomain(feed.URL)
+func (s *Service) FetchFeed(ctx context.Context, feed models.Feed) []models.Article {
+	result := s.fetchChain(ctx, feed)
 
-		// If explicit newsapi, use feed.URL directly (it should be a domain)
-		if feed.Type == "newsapi" {
-			domain = feed.URL
-		}
+	// A cancelled cycle says nothing about the feed's health; it stays due for the next one
+	if ctx.Err() != nil && result.Err != nil {
+		log.Printf("⏱️  Fetch of %s interrupted: %v\n", feed.Name, ctx.Err())
+		return nil
+	}
 
-		if domain != "" {
-			articles, err = s.fetchNewsAPI(feed, domain)
-			if err == nil && len(articles) > 0 {
-				s.markSuccess(feed, "newsapi")
-				return articles
-			}
-			if err != nil {
-				log.Printf("⚠️  NewsAPI failed for %s (%s): %v. Trying Sitemap fallback...", feed.Name, domain, err)
-			}
-		}
+	s.recordResult(feed, result)
+
+	if result.Err != nil {
+		log.Printf("❌ All fetch strategies failed for %s (%s): %v\n", feed.Name, feed.URL, result.Err)
+		return nil
 	}
 
-	// 3. Attempt Sitemap (explicitly sitemap OR fallback)
-	// For fallback, we guess the sitemap URL
-	var sitemapURL string
-	if feed.Type == "sitemap" {
-		sitemapURL = feed.URL
-	} else {
-		// Guessing logic
-		domain := s.extractDomain(feed.URL)
-		if domain != "" {
-			sitemapURL = "https://" + domain + "/sitemap_news.xml" // Most common standard
-		}
+	if len(result.Articles) == 0 {
+		log.Printf("💤 %s not modified since last fetch\n", feed.Name)
+	}
+
+	// Ranking needs the source's type, country, category and trust weight
+	for i := range result.Articles {
+		result.Articles[i].Feed = feed
 	}
 
-	if sitemapURL != "" {
-		// Temporarily modify feed URL for the sitemap fetcher helper
-		feedClone := feed
-		feedClone.URL = sitemapURL
-		articles, err = s.fetchSitemap(feedClone)
-		if err == nil && len(articles) > 0 {
+	return result.Articles
+}
 
-			// Additional filtering for sitemaps
-			var filtered []models.Article
-			for _, a := range articles {
-				if !s.isGossip(a.Title, nil) {
-					filtered = append(filtered, a)
-				}
-			}
-			articles = filtered
+// TestFeed runs the same strategies as FetchFeed without saving anything.
+// Stored cache validators are ignored so the source always returns its items.
+func (s *Service) TestFeed(ctx context.Context, feed models.Feed) FetchResult {
+	feed.ETag, feed.LastModified = "", ""
+	return s.fetchChain(ctx, feed)
+}
 
-			if len(articles) > 0 {
-				s.markSuccess(feed, "sitemap")
-				return articles
+// fetchChain tries the feed's sources in order (see SourceChain) until one returns
+// articles or answers 304 Not Modified
+func (s *Service) fetchChain(ctx context.Context, feed models.Feed) (result FetchResult) {
+	var attempt FetchAttempt
+
+	// Every exit path reports the last error (or a generic one when nothing was found)
+	defer func() {
+		if result.Strategy == "" && result.Err == nil {
+			result.Err = attempt.Err
+			if result.Err == nil {
+				result.Err = errors.New("no items found")
 			}
 		}
+	}()
+
+	chain := SourceChain(feed)
+	if len(chain) == 0 {
+		// Rows written outside the admin (seeds, SQL, old types) may hold anything
+		result.Err = fmt.Errorf("unknown source type %q", feed.Type)
+		return result
 	}
 
-	if err != nil {
-		log.Printf("❌ All fetch strategies failed for %s (%s)\n", feed.Name, feed.URL)
+	for i, name := range chain {
+		source, ok := s.sources[name]
+		if !ok {
+			continue
+		}
+		primary := i == 0
+
+		target := source.Target(feed, primary)
+		if target == "" {
+			continue
+		}
+		if !primary {
+			log.Printf("⚠️  Trying %s fallback for %s (%s)...", name, feed.Name, target)
+		}
+
+		// Validators are only stored for the feed's own source and URL
+		var cache HTTPCache
+		var cachePtr *HTTPCache
+		if primary {
+			cachePtr = &cache
+		}
+
+		var articles []models.Article
+		articles, attempt = s.attempt(name, target, func() ([]models.Article, error) {
+			return source.Fetch(ctx, feed, target, cachePtr)
+		})
+		result.Attempts = append(result.Attempts, attempt)
+
+		if attempt.NotModified || (attempt.Err == nil && len(articles) > 0) {
+			result.Articles, result.Strategy, result.Target, result.Cache = articles, name, target, cache
+			return result
+		}
+		if attempt.Err != nil {
+			log.Printf("⚠️  %s failed for %s (%s): %v", name, feed.Name, target, attempt.Err)
+		}
+		if ctx.Err() != nil {
+			break
+		}
 	}
 
-	return nil
+	return result
 }
 
-func (s *Service) fetchRSS(feed models.Feed) ([]models.Article, error) {
-	feedData, err := s.parser.ParseURL(feed.URL)
+func (s *Service) fetchRSS(ctx context.Context, feed models.Feed, cache *HTTPCache) ([]models.Article, error) {
+	resp, err := s.get(ctx, feed.URL, "RSS", "Gofeed/1.0", feed, cache)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	feedData, err := s.parser.Parse(resp.Body)
 	if err != nil {
 		return nil, err
 	}
 
+	language := strings.ToLower(strings.TrimSpace(feedData.Language))
+
 	articles := make([]models.Article, 0, len(feedData.Items))
 	for _, item := range feedData.Items {
 		// Filter Gossip
@@ -197,6 +335,11 @@ func (s *Service) fetchRSS(feed models.Feed) ([]models.Article, error) {
 			Title:       item.Title,
 			URL:         item.Link,
 			PublishedAt: publishedAt,
+			Summary:     itemSummary(item),
+			Author:      itemAuthor(item),
+			ImageURL:    itemImage(item),
+			Categories:  cleanCategories(item.Categories),
+			Language:    language,
 			FeedID:      feed.ID,
 			Score:       1,
 		})
@@ -204,18 +347,74 @@ func (s *Service) fetchRSS(feed models.Feed) ([]models.Article, error) {
 	return articles, nil
 }
 
-func (s *Service) markSuccess(feed models.Feed, newType string) {
+func (s *Service) markSuccess(feed models.Feed, result FetchResult) {
 	now := time.Now()
-	updates := map[string]interface{}{
-		"last_fetched_at": &now,
+
+	updates := scheduleUpdates(feed, result)
+	updates["last_fetched_at"] = &now
+	updates["last_success_at"] = &now
+	updates["consecutive_failures"] = 0
+	updates["last_error"] = ""
+	updates["etag"] = result.Cache.ETag
+	updates["last_modified"] = result.Cache.LastModified
+
+	if chain := SourceChain(feed); result.Strategy == "" || len(chain) == 0 || result.Strategy == chain[0] {
+		database.DB.Model(&feed).Updates(updates)
+		return
+	}
+
+	if !feed.AutoSwitchType {
+		log.Printf("↪️  %s served by its %s fallback (%s); type stays %s", feed.Name, result.Strategy, result.Target, feed.Type)
+		database.DB.Model(&feed).Updates(updates)
+		return
 	}
 
-	if newType != "" && feed.Type != newType {
-		updates["type"] = newType
-		log.Printf("📝 Updating feed type for %s: %s -> %s", feed.Name, feed.Type, newType)
+	if err := s.switchType(feed, result, updates); err != nil {
+		log.Printf("❌ Could not switch %s to %s: %v\n", feed.Name, result.Strategy, err)
+		database.DB.Model(&feed).Updates(updates)
+	}
+}
+
+// switchType makes the fallback that worked the feed's own source, together with
+// the health updates, and records the change
+func (s *Service) switchType(feed models.Feed, result FetchResult, updates map[string]interface{}) error {
+	change := models.FeedTypeChange{
+		FeedID:   feed.ID,
+		FromType: feed.Type,
+		ToType:   result.Strategy,
+		FromURL:  feed.URL,
+		ToURL:    result.Target,
 	}
+	if len(result.Attempts) > 0 && result.Attempts[0].Err != nil {
+		change.Reason = result.Attempts[0].Err.Error()
+	}
+
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		// URLs are unique: another feed may already be the fallback's source
+		if change.ToURL != change.FromURL {
+			var taken int64
+			if err := tx.Unscoped().Model(&models.Feed{}).Where("url = ? AND id <> ?", change.ToURL, feed.ID).Count(&taken).Error; err != nil {
+				return err
+			}
+			if taken > 0 {
+				return fmt.Errorf("%s is already used by another feed", change.ToURL)
+			}
+		}
+
+		switched := map[string]interface{}{"type": change.ToType, "url": change.ToURL}
+		for k, v := range updates {
+			switched[k] = v
+		}
+		if err := tx.Model(&feed).Updates(switched).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(&change).Error; err != nil {
+			return err
+		}
 
-	database.DB.Model(&feed).Updates(updates)
+		log.Printf("📝 Switched %s from %s (%s) to %s (%s)", feed.Name, change.FromType, change.FromURL, change.ToType, change.ToURL)
+		return nil
+	})
 }
 
 func (s *Service) extractDomain(input string) string {
@@ -245,7 +444,14 @@ var bannedCategories = map[string]bool{
 	"tv": true, // Often reality TV
 }
 
+// isGossip applies the gossip filter, unless the service keeps gossip items
 func (s *Service) isGossip(title string, categories []string) bool {
+	return !s.keepGossip && IsGossip(title, categories)
+}
+
+// IsGossip reports whether an item belongs to the celebrity/reality news the
+// fetcher skips, by its categories or title keywords
+func IsGossip(title string, categories []string) bool {
 	// 1. Check Categories
 	for _, cat := range categories {
 		if bannedCategories[strings.ToLower(cat)] {
@@ -267,9 +473,9 @@ func (s *Service) isGossip(title string, categories []string) bool {
 func (s *Service) saveArticles(db *gorm.DB, articles []models.Article) error {
 	batchSize := 100
 
-	result := db.Clauses(clause.OnConflict{
+	result := db.Omit(clause.Associations).Clauses(clause.OnConflict{
 		Columns:   []clause.Column{{Name: "url"}},
-		DoUpdates: clause.AssignmentColumns([]string{"title", "score", "published_at", "updated_at"}),
+		DoUpdates: clause.AssignmentColumns([]string{"title", "summary", "author", "image_url", "categories", "language", "score", "cluster_count", "published_at", "updated_at"}),
 	}).CreateInBatches(&articles, batchSize)
 
 	if result.Error != nil {
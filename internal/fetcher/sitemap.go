@@ -1,77 +1,332 @@
 package fetcher
 
 import (
+	"bufio"
+	"compress/gzip"
+	"context"
 	"encoding/xml"
+	"errors"
 	"fmt"
-	"net/http"
+	"io"
+	"log"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
 	"time"
+	"unicode"
 	"vidit/internal/models"
 )
 
-// Sitemap represents the root of a Google News Sitemap
+const (
+	maxSitemapBytes    = 50 << 20       // Protocol limit for an uncompressed sitemap
+	maxChildSitemaps   = 3              // Most recent children of a sitemap index that are followed
+	maxSitemapDepth    = 2              // Indexes of indexes are followed once
+	plainSitemapMaxAge = 48 * time.Hour // Older entries of sitemaps without news tags are ignored
+)
+
+// Sitemap represents the root of a sitemap: a urlset (Google News or plain)
+// or a sitemapindex pointing at other sitemaps
 type Sitemap struct {
-	XMLName xml.Name     `xml:"urlset"`
-	URLs    []SitemapURL `xml:"url"`
+	XMLName  xml.Name
+	URLs     []SitemapURL `xml:"url"`
+	Sitemaps []SitemapRef `xml:"sitemap"`
 }
 
 // SitemapURL represents a single URL entry in the sitemap
 type SitemapURL struct {
-	Loc  string      `xml:"loc"`
-	News SitemapNews `xml:"news"`
+	Loc     string         `xml:"loc"`
+	LastMod string         `xml:"lastmod"`
+	News    SitemapNews    `xml:"news"`
+	Images  []SitemapImage `xml:"image"`
 }
 
 // SitemapNews contains the Google News specific tags
 type SitemapNews struct {
-	PublicationDate string `xml:"publication_date"`
-	Title           string `xml:"title"`
+	Publication     SitemapPublication `xml:"publication"`
+	PublicationDate string             `xml:"publication_date"`
+	Title           string             `xml:"title"`
+	Keywords        string             `xml:"keywords"`
+}
+
+// SitemapPublication is the news:publication of an entry
+type SitemapPublication struct {
+	Name     string `xml:"name"`
+	Language string `xml:"language"` // ISO 639, e.g. "es" or "zh-cn"
+}
+
+// SitemapImage is an image:image entry (Google Image sitemap extension)
+type SitemapImage struct {
+	Loc string `xml:"loc"`
 }
 
-// fetchSitemap downloads and parses a Google News Sitemap
-func (s *Service) fetchSitemap(feed models.Feed) ([]models.Article, error) {
-	client := http.Client{
-		Timeout: 10 * time.Second,
+// SitemapRef is a child sitemap listed by a sitemapindex
+type SitemapRef struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod"`
+}
+
+// publisherDateLayouts are the date formats seen in sitemaps and scraped pages, W3C first.
+var publisherDateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05Z0700",
+	"2006-01-02T15:04Z07:00",
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05Z07:00",
+	"2006-01-02 15:04:05 -0700",
+	"2006-01-02 15:04:05 MST",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+	time.RFC1123Z,
+	time.RFC1123,
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	"Mon, 2 Jan 2006 15:04:05 MST",
+	time.RFC850,
+	"2006/01/02 15:04:05",
+}
+
+// fetchSitemap downloads and parses a sitemap. Google News entries use their own
+// tags; sitemap indexes are followed down to their most recent children.
+func (s *Service) fetchSitemap(ctx context.Context, feed models.Feed, cache *HTTPCache) ([]models.Article, error) {
+	return s.fetchSitemapURL(ctx, feed, feed.URL, cache, 0)
+}
+
+func (s *Service) fetchSitemapURL(ctx context.Context, feed models.Feed, sitemapURL string, cache *HTTPCache, depth int) ([]models.Article, error) {
+	sitemap, err := s.getSitemap(ctx, feed, sitemapURL, cache)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(sitemap.Sitemaps) > 0 {
+		if depth >= maxSitemapDepth {
+			return nil, fmt.Errorf("sitemap index %s nested too deep", sitemapURL)
+		}
+		// An unchanged index says nothing about its children, so no validators are
+		// kept for it: a 304 next time would skip children that did change
+		if cache != nil {
+			*cache = HTTPCache{}
+		}
+		return s.fetchSitemapIndex(ctx, feed, sitemapURL, sitemap.Sitemaps, depth)
 	}
 
-	resp, err := client.Get(feed.URL)
+	return sitemapArticles(feed, sitemap.URLs), nil
+}
+
+// getSitemap downloads and decodes one sitemap, gzipped or not
+func (s *Service) getSitemap(ctx context.Context, feed models.Feed, sitemapURL string, cache *HTTPCache) (*Sitemap, error) {
+	resp, err := s.get(ctx, sitemapURL, "sitemap", "", feed, cache)
 	if err != nil {
+		if errors.Is(err, errNotModified) {
+			return nil, err
+		}
 		return nil, fmt.Errorf("failed to fetch sitemap: %w", err)
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("sitemap returned status: %d", resp.StatusCode)
+	// .xml.gz files are usually served as-is rather than with Content-Encoding,
+	// so the body is sniffed for the gzip magic number
+	body := bufio.NewReader(resp.Body)
+	var r io.Reader = body
+	if magic, _ := body.Peek(2); len(magic) == 2 && magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, err := gzip.NewReader(body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress sitemap: %w", err)
+		}
+		defer gz.Close()
+		r = gz
 	}
 
 	var sitemap Sitemap
-	if err := xml.NewDecoder(resp.Body).Decode(&sitemap); err != nil {
+	if err := xml.NewDecoder(io.LimitReader(r, maxSitemapBytes)).Decode(&sitemap); err != nil {
 		return nil, fmt.Errorf("failed to decode sitemap XML: %w", err)
 	}
+	return &sitemap, nil
+}
+
+// fetchSitemapIndex follows the most recently modified children of an index
+// (news sitemaps first). Children that fail are skipped as long as one works.
+func (s *Service) fetchSitemapIndex(ctx context.Context, feed models.Feed, indexURL string, refs []SitemapRef, depth int) ([]models.Article, error) {
+	type child struct {
+		url     string
+		lastMod time.Time
+		news    bool
+	}
 
-	articles := make([]models.Article, 0, len(sitemap.URLs))
-	for _, url := range sitemap.URLs {
-		// Skip if no title (some sitemaps might be index sitemaps, we ignore those for now)
-		if url.News.Title == "" {
+	var children []child
+	for _, ref := range refs {
+		loc := safeURL(ref.Loc, indexURL)
+		if loc == "" {
 			continue
 		}
+		lastMod, _ := parsePublisherDate(ref.LastMod)
+		children = append(children, child{url: loc, lastMod: lastMod, news: strings.Contains(strings.ToLower(loc), "news")})
+	}
 
-		publishedAt := time.Now()
-		if url.News.PublicationDate != "" {
-			// Try parsing ISO8601 variations
-			if t, err := time.Parse(time.RFC3339, url.News.PublicationDate); err == nil {
-				publishedAt = t
-			} else if t, err := time.Parse("2006-01-02T15:04:05-07:00", url.News.PublicationDate); err == nil {
+	sort.SliceStable(children, func(i, j int) bool {
+		if children[i].news != children[j].news {
+			return children[i].news
+		}
+		return children[i].lastMod.After(children[j].lastMod)
+	})
+	if len(children) > maxChildSitemaps {
+		children = children[:maxChildSitemaps]
+	}
+
+	log.Printf("🗺️  Sitemap index %s: following %d of %d sitemaps", indexURL, len(children), len(refs))
+
+	var articles []models.Article
+	var firstErr error
+	seen := make(map[string]bool)
+	for _, c := range children {
+		// Validators are only kept for the feed's own URL
+		found, err := s.fetchSitemapURL(ctx, feed, c.url, nil, depth+1)
+		if err != nil {
+			log.Printf("⚠️  Child sitemap %s failed: %v", c.url, err)
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		for _, a := range found {
+			if !seen[a.URL] {
+				seen[a.URL] = true
+				articles = append(articles, a)
+			}
+		}
+	}
+
+	if len(articles) == 0 && firstErr != nil {
+		return nil, firstErr
+	}
+	return articles, nil
+}
+
+// sitemapArticles turns the entries of a urlset into articles. News entries are
+// dated by news:publication_date, falling back to lastmod; entries of plain
+// sitemaps have no title, so only recent ones with a readable URL slug are kept.
+func sitemapArticles(feed models.Feed, urls []SitemapURL) []models.Article {
+	articles := make([]models.Article, 0, len(urls))
+	var unparsable []string
+
+	for _, u := range urls {
+		loc := safeURL(u.Loc, feed.URL)
+		if loc == "" {
+			continue
+		}
+
+		lastMod, hasLastMod := parsePublisherDate(u.LastMod)
+
+		title := strings.TrimSpace(u.News.Title)
+		if title == "" {
+			if !hasLastMod || time.Since(lastMod) > plainSitemapMaxAge {
+				continue
+			}
+			if title = titleFromURL(loc); title == "" {
+				continue
+			}
+		}
+
+		var publishedAt time.Time
+		date := strings.TrimSpace(u.News.PublicationDate)
+		if date != "" {
+			if t, ok := parsePublisherDate(date); ok {
 				publishedAt = t
+			} else {
+				unparsable = append(unparsable, date)
 			}
 		}
+		if publishedAt.IsZero() && hasLastMod {
+			publishedAt = lastMod
+		}
+		if publishedAt.IsZero() {
+			if date != "" {
+				continue // A date we can't read says nothing about how fresh the entry is
+			}
+			publishedAt = time.Now() // Undated news entry, news sitemaps only list recent ones
+		}
 
+		var imageURL string
+		for _, img := range u.Images {
+			if imageURL = safeURL(img.Loc, loc); imageURL != "" {
+				break
+			}
+		}
+
+		// Sitemaps carry no lead; their keywords are the closest thing to categories
 		articles = append(articles, models.Article{
-			Title:       url.News.Title,
-			URL:         url.Loc,
+			Title:       title,
+			URL:         loc,
 			PublishedAt: publishedAt,
+			ImageURL:    imageURL,
+			Categories:  cleanCategories(strings.Split(u.News.Keywords, ",")),
+			Language:    strings.ToLower(strings.TrimSpace(u.News.Publication.Language)),
 			FeedID:      feed.ID,
 			Score:       1, // Default score
 		})
 	}
 
-	return articles, nil
+	if len(unparsable) > 0 {
+		log.Printf("⚠️  %s: %d sitemap dates could not be parsed (e.g. %q)", feed.Name, len(unparsable), unparsable[0])
+	}
+
+	return articles
+}
+
+// parsePublisherDate reads a date in any of publisherDateLayouts, as UTC if it has no zone
+func parsePublisherDate(value string) (time.Time, bool) {
+	return parsePublisherDateIn(value, time.UTC)
+}
+
+// parsePublisherDateIn is parsePublisherDate with dates without a zone read in loc
+func parsePublisherDateIn(value string, loc *time.Location) (time.Time, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, false
+	}
+	for _, layout := range publisherDateLayouts {
+		if t, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// titleFromURL builds a title from an article slug
+// ("/2024/05/02/boric-anuncia-reforma-123456.html" -> "Boric anuncia reforma").
+// Returns "" when the URL has no slug of at least three words.
+func titleFromURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+
+	segments := strings.Split(strings.Trim(u.Path, "/"), "/")
+	for i := len(segments) - 1; i >= 0; i-- {
+		slug := strings.TrimSuffix(segments[i], path.Ext(segments[i]))
+
+		var words []string
+		for _, w := range strings.FieldsFunc(slug, func(r rune) bool { return r == '-' || r == '_' || r == '+' }) {
+			if hasLetters(w) {
+				words = append(words, w)
+			}
+		}
+		if len(words) < 3 {
+			continue
+		}
+
+		title := []rune(strings.Join(words, " "))
+		title[0] = unicode.ToUpper(title[0])
+		return string(title)
+	}
+	return ""
+}
+
+func hasLetters(s string) bool {
+	for _, r := range s {
+		if unicode.IsLetter(r) {
+			return true
+		}
+	}
+	return false
 }
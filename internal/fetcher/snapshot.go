@@ -0,0 +1,58 @@
+package fetcher
+
+import (
+	"sort"
+	"vidit/internal/models"
+)
+
+// SnapshotStories ranks the articles and groups them into stories in memory, the
+// same way saveStories does, without touching the database. Used to rebuild past
+// front pages (set rs.Now to the moment to rebuild) and filtered slices.
+// The returned stories have no ID and are sorted by score (descending).
+func (rs *RankingService) SnapshotStories(articles []models.Article) []models.Story {
+	articles = rs.Rank(articles)
+	threshold := rs.config().ThresholdDedup
+
+	index := newStoryIndex()
+	var stories []models.Story
+
+	// Best first, so the lead of each story is its highest scored article
+	for _, article := range articles {
+		tokens := rs.Tokenize(article.Title)
+
+		if i, ok := index.match(rs, tokens, threshold); ok {
+			story := &stories[i]
+			story.Articles = append(story.Articles, article)
+			story.ArticleCount++
+			if article.PublishedAt.Before(story.FirstSeenAt) {
+				story.FirstSeenAt = article.PublishedAt
+			}
+			if article.PublishedAt.After(story.LastSeenAt) {
+				story.LastSeenAt = article.PublishedAt
+			}
+			index.add(i, tokens)
+			continue
+		}
+
+		index.add(uint(len(stories)), tokens)
+		stories = append(stories, models.Story{
+			Title:         article.Title,
+			Score:         article.Score,
+			ArticleCount:  1,
+			FirstSeenAt:   article.PublishedAt,
+			LastSeenAt:    article.PublishedAt,
+			LeadArticleID: article.ID,
+			LeadArticle:   article,
+			Articles:      []models.Article{article},
+		})
+	}
+
+	sort.SliceStable(stories, func(i, j int) bool {
+		if stories[i].Score != stories[j].Score {
+			return stories[i].Score > stories[j].Score
+		}
+		return stories[i].LastSeenAt.After(stories[j].LastSeenAt)
+	})
+
+	return stories
+}
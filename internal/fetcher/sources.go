@@ -0,0 +1,151 @@
+package fetcher
+
+import (
+	"context"
+	"sort"
+
+	"vidit/internal/models"
+)
+
+// Source is a way of getting articles for a feed (an RSS parser, an API, a sitemap...).
+// A feed is fetched with the source named by its type, then with its fallbacks in order.
+type Source interface {
+	// Target is the URL or domain this source would fetch for the feed, or "" if it
+	// can't serve it. primary is false when the source is a fallback of another type.
+	Target(feed models.Feed, primary bool) string
+
+	// Fetch gets the articles at target. cache is nil for fallbacks: validators are
+	// only stored for the feed's own source.
+	Fetch(ctx context.Context, feed models.Feed, target string, cache *HTTPCache) ([]models.Article, error)
+}
+
+type sourceEntry struct {
+	factory   func(*Service) Source
+	fallbacks []string
+}
+
+var sourceRegistry = map[string]sourceEntry{}
+
+// RegisterSource adds a source kind, usable as a feed type. fallbacks is the chain
+// tried when a feed of this type doesn't declare its own.
+func RegisterSource(name string, factory func(*Service) Source, fallbacks ...string) {
+	sourceRegistry[name] = sourceEntry{factory: factory, fallbacks: fallbacks}
+}
+
+func init() {
+	RegisterSource("rss", newFeedSource, "newsapi", "sitemap")
+	RegisterSource("atom", newFeedSource, "newsapi", "sitemap")
+	RegisterSource("newsapi", newNewsAPISource, "sitemap")
+	RegisterSource("sitemap", newSitemapSource)
+	RegisterSource("scrape", newScrapeSource)
+}
+
+// SourceNames lists the registered source kinds, sorted
+func SourceNames() []string {
+	names := make([]string, 0, len(sourceRegistry))
+	for name := range sourceRegistry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// IsSource reports whether name is a registered source kind
+func IsSource(name string) bool {
+	_, ok := sourceRegistry[name]
+	return ok
+}
+
+// SourceChain is the ordered list of sources tried for a feed: its type, then its
+// own fallbacks, or the type's default ones when the feed doesn't set any.
+// Unknown names and repeats are skipped; a feed of an unknown type has no chain.
+func SourceChain(feed models.Feed) []string {
+	primary := feed.Type
+	if primary == "" {
+		primary = "rss"
+	}
+	if !IsSource(primary) {
+		return nil
+	}
+
+	fallbacks := feed.Fallbacks
+	if fallbacks == nil {
+		fallbacks = sourceRegistry[primary].fallbacks
+	}
+
+	chain := []string{primary}
+	seen := map[string]bool{primary: true}
+	for _, name := range fallbacks {
+		if IsSource(name) && !seen[name] {
+			seen[name] = true
+			chain = append(chain, name)
+		}
+	}
+	return chain
+}
+
+// feedSource parses RSS and Atom documents; both types share it
+type feedSource struct{ s *Service }
+
+func newFeedSource(s *Service) Source { return feedSource{s} }
+
+// Target is only the feed's own URL: other pages of the site aren't feeds
+func (src feedSource) Target(feed models.Feed, primary bool) string {
+	if !primary {
+		return ""
+	}
+	return feed.URL
+}
+
+func (src feedSource) Fetch(ctx context.Context, feed models.Feed, target string, cache *HTTPCache) ([]models.Article, error) {
+	feed.URL = target
+	return src.s.fetchRSS(ctx, feed, cache)
+}
+
+type newsAPISource struct{ s *Service }
+
+func newNewsAPISource(s *Service) Source { return newsAPISource{s} }
+
+// Target is the domain to search: the feed's URL is already one for newsapi feeds
+func (src newsAPISource) Target(feed models.Feed, primary bool) string {
+	if primary {
+		return feed.URL
+	}
+	return src.s.extractDomain(feed.URL)
+}
+
+func (src newsAPISource) Fetch(ctx context.Context, feed models.Feed, target string, cache *HTTPCache) ([]models.Article, error) {
+	return src.s.fetchNewsAPI(ctx, feed, target)
+}
+
+type sitemapSource struct{ s *Service }
+
+func newSitemapSource(s *Service) Source { return sitemapSource{s} }
+
+// Target guesses the most common news sitemap location when falling back
+func (src sitemapSource) Target(feed models.Feed, primary bool) string {
+	if primary {
+		return feed.URL
+	}
+	if domain := src.s.extractDomain(feed.URL); domain != "" {
+		return "https://" + domain + "/sitemap_news.xml"
+	}
+	return ""
+}
+
+func (src sitemapSource) Fetch(ctx context.Context, feed models.Feed, target string, cache *HTTPCache) ([]models.Article, error) {
+	feed.URL = target
+	articles, err := src.s.fetchSitemap(ctx, feed, cache)
+	if err != nil {
+		return nil, err
+	}
+
+	// Sitemaps carry no categories, so only titles can be filtered
+	var filtered []models.Article
+	for _, a := range articles {
+		if !src.s.isGossip(a.Title, nil) {
+			filtered = append(filtered, a)
+		}
+	}
+	return filtered, nil
+}
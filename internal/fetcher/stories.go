@@ -0,0 +1,233 @@
+package fetcher
+
+import (
+	"log"
+	"time"
+
+	"vidit/internal/minhash"
+	"vidit/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// StoryWindow is how long a story stays "open" for new articles after its latest one
+const StoryWindow = 48 * time.Hour
+
+// storyIndex finds the open story an article belongs to by comparing it with every member title
+type storyIndex struct {
+	lsh     *minhash.Index
+	tokens  []map[string]bool // Per member, by LSH ID
+	stories []uint            // Story of each member, by LSH ID
+	open    map[uint]bool
+}
+
+func newStoryIndex() *storyIndex {
+	return &storyIndex{
+		lsh:  minhash.NewIndex(minhash.DefaultBands, minhash.DefaultRows),
+		open: make(map[uint]bool),
+	}
+}
+
+func (si *storyIndex) add(storyID uint, tokens map[string]bool) {
+	si.lsh.Add(tokens)
+	si.tokens = append(si.tokens, tokens)
+	si.stories = append(si.stories, storyID)
+	si.open[storyID] = true
+}
+
+// match returns the story of the oldest member similar enough to tokens
+func (si *storyIndex) match(rs *RankingService, tokens map[string]bool, threshold float64) (uint, bool) {
+	for _, id := range si.lsh.Query(tokens) {
+		if rs.Similarity(tokens, si.tokens[id]) > threshold {
+			return si.stories[id], true
+		}
+	}
+	return 0, false
+}
+
+// saveStories assigns every article to an existing story or opens a new one.
+// Articles must already be saved and sorted by score (descending), so the best
+// version of an event becomes the lead of any new story.
+func (s *Service) saveStories(db *gorm.DB, rs *RankingService, articles []models.Article) error {
+	// 1. Resolve IDs by URL (upserted rows may not carry them back)
+	ids, err := s.articleIDsByURL(db, articles)
+	if err != nil {
+		return err
+	}
+
+	articleIDs := make([]uint, 0, len(ids))
+	for _, id := range ids {
+		articleIDs = append(articleIDs, id)
+	}
+
+	// 2. Existing memberships
+	assigned := make(map[uint]uint) // ArticleID -> StoryID
+	for i := 0; i < len(articleIDs); i += 500 {
+		end := i + 500
+		if end > len(articleIDs) {
+			end = len(articleIDs)
+		}
+		var links []models.StoryArticle
+		if err := db.Where("article_id IN ?", articleIDs[i:end]).Find(&links).Error; err != nil {
+			return err
+		}
+		for _, l := range links {
+			assigned[l.ArticleID] = l.StoryID
+		}
+	}
+
+	// 3. Open stories from previous cycles
+	var openStories []models.Story
+	err = db.Preload("Articles", func(tx *gorm.DB) *gorm.DB {
+		return tx.Select("articles.id", "articles.title")
+	}).Where("last_seen_at > ?", time.Now().Add(-StoryWindow)).Find(&openStories).Error
+	if err != nil {
+		return err
+	}
+
+	index := newStoryIndex()
+	for _, story := range openStories {
+		for _, a := range story.Articles {
+			index.add(story.ID, rs.Tokenize(a.Title))
+		}
+	}
+
+	// 4. Assign articles, best first
+	threshold := rs.config().ThresholdDedup
+	var newLinks []models.StoryArticle
+	touched := make(map[uint]bool)
+	created := 0
+
+	for _, article := range articles {
+		articleID := ids[article.URL]
+		if articleID == 0 {
+			continue
+		}
+		tokens := rs.Tokenize(article.Title)
+
+		if storyID, ok := assigned[articleID]; ok {
+			touched[storyID] = true
+			// Make sure later articles can match it even if the story is older than the window
+			if index.open[storyID] {
+				index.add(storyID, tokens)
+			}
+			continue
+		}
+
+		storyID, ok := index.match(rs, tokens, threshold)
+		if !ok {
+			story := models.Story{
+				Title:         article.Title,
+				Score:         article.Score,
+				ArticleCount:  1,
+				FirstSeenAt:   article.PublishedAt,
+				LastSeenAt:    article.PublishedAt,
+				LeadArticleID: articleID,
+			}
+			if err := db.Omit(clause.Associations).Create(&story).Error; err != nil {
+				log.Printf("❌ Error creating story for %q: %v\n", article.Title, err)
+				continue
+			}
+			storyID = story.ID
+			created++
+		}
+
+		index.add(storyID, tokens)
+		assigned[articleID] = storyID
+		touched[storyID] = true
+		newLinks = append(newLinks, models.StoryArticle{StoryID: storyID, ArticleID: articleID})
+	}
+
+	if len(newLinks) > 0 {
+		err := db.Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(&newLinks, 500).Error
+		if err != nil {
+			log.Printf("❌ Error linking articles to stories: %v\n", err)
+			return err
+		}
+	}
+
+	storyIDs := make([]uint, 0, len(touched))
+	for id := range touched {
+		storyIDs = append(storyIDs, id)
+	}
+
+	if err := RefreshStories(db, storyIDs); err != nil {
+		return err
+	}
+
+	log.Printf("🧵 Stories: %d new, %d articles linked, %d refreshed\n", created, len(newLinks), len(storyIDs))
+	return nil
+}
+
+func (s *Service) articleIDsByURL(db *gorm.DB, articles []models.Article) (map[string]uint, error) {
+	urls := make([]string, 0, len(articles))
+	for _, a := range articles {
+		urls = append(urls, a.URL)
+	}
+
+	ids := make(map[string]uint, len(urls))
+	for i := 0; i < len(urls); i += 500 {
+		end := i + 500
+		if end > len(urls) {
+			end = len(urls)
+		}
+		var rows []models.Article
+		if err := db.Select("id", "url").Where("url IN ?", urls[i:end]).Find(&rows).Error; err != nil {
+			return nil, err
+		}
+		for _, r := range rows {
+			ids[r.URL] = r.ID
+		}
+	}
+	return ids, nil
+}
+
+// RefreshStories recomputes the denormalized columns (lead, title, score, counts and dates)
+// of the given stories from their member articles.
+func RefreshStories(db *gorm.DB, storyIDs []uint) error {
+	for i := 0; i < len(storyIDs); i += 500 {
+		end := i + 500
+		if end > len(storyIDs) {
+			end = len(storyIDs)
+		}
+		batch := storyIDs[i:end]
+
+		err := db.Exec(`
+			UPDATE stories SET
+				article_count = s.cnt,
+				score = s.max_score,
+				first_seen_at = s.first_seen,
+				last_seen_at = s.last_seen,
+				updated_at = NOW()
+			FROM (
+				SELECT sa.story_id, COUNT(*) AS cnt, MAX(a.score) AS max_score,
+					MIN(a.published_at) AS first_seen, MAX(a.published_at) AS last_seen
+				FROM story_articles sa
+				JOIN articles a ON a.id = sa.article_id AND a.deleted_at IS NULL
+				WHERE sa.story_id IN ?
+				GROUP BY sa.story_id
+			) s
+			WHERE stories.id = s.story_id`, batch).Error
+		if err != nil {
+			return err
+		}
+
+		err = db.Exec(`
+			UPDATE stories SET
+				lead_article_id = l.article_id,
+				title = l.title
+			FROM (
+				SELECT DISTINCT ON (sa.story_id) sa.story_id, a.id AS article_id, a.title
+				FROM story_articles sa
+				JOIN articles a ON a.id = sa.article_id AND a.deleted_at IS NULL
+				WHERE sa.story_id IN ?
+				ORDER BY sa.story_id, a.score DESC, a.published_at DESC
+			) l
+			WHERE stories.id = l.story_id`, batch).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,194 @@
+package fetcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"vidit/internal/models"
+)
+
+// Thresholds of the validation warnings
+const (
+	staleAfter      = 7 * 24 * time.Hour
+	maxGossipRatio  = 0.5
+	validateWorkers = DefaultWorkers
+)
+
+// FeedReport is the validation result of one feed
+type FeedReport struct {
+	Name        string     `json:"name"`
+	URL         string     `json:"url"`
+	Type        string     `json:"type"`
+	Strategy    string     `json:"strategy,omitempty"` // Source that returned the items
+	StatusCode  int        `json:"status_code"`        // 0 when no HTTP response was received
+	Parse       string     `json:"parse"`              // "ok" or the error
+	Items       int        `json:"items"`
+	Newest      *time.Time `json:"newest,omitempty"`
+	GossipRatio float64    `json:"gossip_ratio"` // Share of items the gossip filter would drop
+	LatencyMs   int64      `json:"latency_ms"`
+	Warnings    []string   `json:"warnings,omitempty"`
+}
+
+// OK reports whether the feed returned items
+func (r FeedReport) OK() bool {
+	return r.Parse == "ok"
+}
+
+// NewestAge is how old the newest item is, or 0 if there is none
+func (r FeedReport) NewestAge() time.Duration {
+	if r.Newest == nil {
+		return 0
+	}
+	return time.Since(*r.Newest)
+}
+
+// ValidateOptions tune ValidateFeeds
+type ValidateOptions struct {
+	Fallbacks bool // Also try each feed's fallback chain, as the fetcher would
+	Workers   int  // Feeds validated at the same time (DefaultWorkers if 0)
+}
+
+// ValidateFeeds fetches every feed once with its real source, without saving
+// anything, and reports how it did. Reports keep the order of feeds.
+func ValidateFeeds(ctx context.Context, feeds []models.Feed, opts ValidateOptions) []FeedReport {
+	s := NewService().KeepGossip()
+
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = validateWorkers
+	}
+
+	reports := make([]FeedReport, len(feeds))
+	sem := make(chan struct{}, workers)
+	var wg sync.WaitGroup
+
+	for i, feed := range feeds {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, feed models.Feed) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			reports[i] = s.validateFeed(ctx, feed, opts.Fallbacks)
+		}(i, feed)
+	}
+	wg.Wait()
+
+	warnSharedHosts(feeds, reports)
+	return reports
+}
+
+func (s *Service) validateFeed(ctx context.Context, feed models.Feed, fallbacks bool) FeedReport {
+	if feed.Type == "" {
+		feed.Type = "rss"
+	}
+	report := FeedReport{Name: feed.Name, URL: feed.URL, Type: feed.Type}
+
+	if !IsSource(feed.Type) {
+		report.Parse = fmt.Sprintf("unknown source type %q", feed.Type)
+		return report
+	}
+	if !fallbacks {
+		feed.Fallbacks = []string{}
+	}
+
+	start := time.Now()
+	result := s.TestFeed(ctx, feed)
+	report.LatencyMs = time.Since(start).Milliseconds()
+	report.Strategy = result.Strategy
+
+	// The status that matters is the one of the source that answered, or else the feed's own
+	for _, a := range result.Attempts {
+		if a.Strategy == result.Strategy || report.StatusCode == 0 {
+			report.StatusCode = a.StatusCode
+		}
+	}
+
+	if result.Err != nil {
+		report.Parse = result.Err.Error()
+		var statusErr *StatusError
+		if errors.As(result.Err, &statusErr) {
+			report.Parse = "—"
+		}
+		return report
+	}
+
+	report.Parse = "ok"
+	report.Items = len(result.Articles)
+
+	var newest time.Time
+	gossip := 0
+	for _, a := range result.Articles {
+		if a.PublishedAt.After(newest) {
+			newest = a.PublishedAt
+		}
+		if IsGossip(a.Title, a.Categories) {
+			gossip++
+		}
+	}
+	if !newest.IsZero() {
+		report.Newest = &newest
+	}
+	if report.Items > 0 {
+		report.GossipRatio = float64(gossip) / float64(report.Items)
+	}
+
+	if result.Strategy != feed.Type {
+		report.Warnings = append(report.Warnings, fmt.Sprintf("served by the %s fallback (%s)", result.Strategy, result.Target))
+	}
+	if report.Newest != nil && report.NewestAge() > staleAfter {
+		report.Warnings = append(report.Warnings, fmt.Sprintf("newest item is %d days old", int(report.NewestAge().Hours()/24)))
+	}
+	if report.GossipRatio > maxGossipRatio {
+		report.Warnings = append(report.Warnings, fmt.Sprintf("%.0f%% of items are gossip", report.GossipRatio*100))
+	}
+	return report
+}
+
+// warnSharedHosts flags feeds pointing at the same URL or host, which usually
+// means the same outlet would be fetched (and ranked) twice
+func warnSharedHosts(feeds []models.Feed, reports []FeedReport) {
+	urlOf := func(i int) string { return strings.TrimSuffix(feeds[i].URL, "/") }
+
+	byHost := make(map[string][]int)
+	for i, f := range feeds {
+		if host := feedHost(f); host != "" {
+			byHost[host] = append(byHost[host], i)
+		}
+	}
+
+	for host, group := range byHost {
+		for _, i := range group {
+			var sameURL, sameHost []string
+			for _, j := range group {
+				switch {
+				case j == i:
+				case urlOf(j) == urlOf(i):
+					sameURL = append(sameURL, feeds[j].Name)
+				default:
+					sameHost = append(sameHost, feeds[j].Name)
+				}
+			}
+			if len(sameURL) > 0 {
+				sort.Strings(sameURL)
+				reports[i].Warnings = append(reports[i].Warnings, "same URL as "+strings.Join(sameURL, ", "))
+			}
+			if len(sameHost) > 0 {
+				sort.Strings(sameHost)
+				reports[i].Warnings = append(reports[i].Warnings, fmt.Sprintf("host %s shared with %s", host, strings.Join(sameHost, ", ")))
+			}
+		}
+	}
+}
+
+// feedHost is the site of a feed; newsapi feeds already hold a bare domain
+func feedHost(feed models.Feed) string {
+	if !strings.Contains(feed.URL, "://") {
+		return strings.TrimPrefix(strings.ToLower(feed.URL), "www.")
+	}
+	return strings.ToLower(hostKey(feed.URL))
+}
@@ -0,0 +1,418 @@
+// Package keyphrase picks the words and names that best describe a story, looking
+// at every title of its cluster at once: capitalized multi-word names ("Delcy
+// Rodríguez"), plus single terms scored by TF-IDF against the archive. Results
+// come with a hashtag-ready form for social lookups (#DelcyRodriguez).
+package keyphrase
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+
+	"vidit/internal/textproc"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Phrase is a keyword candidate of a story
+type Phrase struct {
+	Text    string  // Most common spelling in the titles, e.g. "Delcy Rodríguez"
+	Hashtag string  // e.g. "#DelcyRodriguez"
+	Score   float64 // Titles mentioning it × IDF × kind weight
+	Count   int     // Titles mentioning it
+	Entity  bool    // Capitalized name rather than a plain term
+}
+
+// Weights of each kind of candidate, on top of TF-IDF
+const (
+	weightTerm       = 1.0
+	weightProperNoun = 1.3 // A single capitalized word in the middle of a title
+	weightEntity     = 1.5 // Multi-word names, plus weightPerWord for each extra word
+	weightPerWord    = 0.5
+
+	minTermLength = 4 // Runes; shorter terms make poor hashtags
+)
+
+// connectors may appear inside a name ("Banco Central de Chile") but not at its ends
+var connectors = map[string]bool{
+	"de": true, "del": true, "la": true, "las": true, "los": true, "y": true, "e": true,
+	"of": true, "the": true, "and": true,
+}
+
+// Corpus holds the document frequencies of the archive, for the IDF term
+type Corpus struct {
+	docs int
+	df   map[string]int // Stem -> titles containing it
+}
+
+// NewCorpus counts, for every stem, how many of the titles contain it
+func NewCorpus(titles []string) *Corpus {
+	c := &Corpus{docs: len(titles), df: make(map[string]int)}
+	for _, t := range titles {
+		for stem := range textproc.TokenSet(t) {
+			c.df[stem]++
+		}
+	}
+	return c
+}
+
+// Docs is the number of titles in the corpus
+func (c *Corpus) Docs() int {
+	if c == nil {
+		return 0
+	}
+	return c.docs
+}
+
+// IDF is the smoothed inverse document frequency of a phrase, taken from its
+// rarest stem (a phrase is at most as common as any of its words).
+// A nil corpus weighs everything the same.
+func (c *Corpus) IDF(stems []string) float64 {
+	if c == nil || c.docs == 0 || len(stems) == 0 {
+		return 1
+	}
+	best := 0.0
+	for _, s := range stems {
+		idf := math.Log(float64(c.docs+1)/float64(c.df[s]+1)) + 1
+		if idf > best {
+			best = idf
+		}
+	}
+	return best
+}
+
+// word is a word of a title with the punctuation that surrounds it
+type word struct {
+	text    string // Without surrounding punctuation
+	folded  string
+	index   int  // Position in the title
+	breaks  bool // Punctuation after it ends a name
+	opens   bool // Punctuation before it starts a new name
+	capital bool
+}
+
+// candidate accumulates the mentions of a phrase across the cluster
+type candidate struct {
+	stems    []string
+	forms    map[string]int
+	titles   map[int]bool
+	entity   bool
+	proper   bool
+	words    int
+	firstPos int // For stable ordering
+}
+
+// Extract returns up to n phrases describing the cluster of titles, best first.
+// corpus may be nil, in which case only frequency within the cluster counts.
+func Extract(titles []string, corpus *Corpus, n int) []Phrase {
+	candidates := make(map[string]*candidate)
+	order := 0
+
+	add := func(key string, stems []string, form string, title int, entity, proper bool, words int) {
+		c, ok := candidates[key]
+		if !ok {
+			c = &candidate{stems: stems, forms: make(map[string]int), titles: make(map[int]bool), words: words, firstPos: order}
+			candidates[key] = c
+			order++
+		}
+		c.forms[form]++
+		c.titles[title] = true
+		c.entity = c.entity || entity
+		c.proper = c.proper || proper
+	}
+
+	folded := make([]string, len(titles))
+	for ti, title := range titles {
+		folded[ti] = " " + strings.Join(textproc.Words(title), " ") + " "
+		words := splitWords(title)
+		lang := textproc.DetectLanguage(foldedWords(words))
+
+		// Title Case headlines ("Trump Says New Tariffs...") capitalize everything,
+		// so capitals say nothing about names there
+		titleCase := capitalRatio(words) > 0.6
+
+		if !titleCase {
+			for _, run := range nameRuns(words) {
+				text, stems := phraseOf(run, lang)
+				if len(stems) == 0 {
+					continue
+				}
+				if len(run) == 1 {
+					add(stems[0], stems, text, ti, false, true, 1)
+				} else {
+					add(foldedKey(run), stems, text, ti, true, false, len(run))
+				}
+			}
+		}
+
+		for _, w := range words {
+			if len([]rune(w.folded)) < minTermLength || textproc.IsStopword(w.folded) || !hasLetter(w.folded) {
+				continue
+			}
+			stem := textproc.Stem(strings.ToLower(w.text), lang)
+			add(stem, []string{stem}, lowerUnlessName(w), ti, false, false, 1)
+		}
+	}
+
+	// Names also count the titles that mention them without capitals
+	for key, c := range candidates {
+		if !c.entity {
+			continue
+		}
+		for ti, f := range folded {
+			if strings.Contains(f, " "+key+" ") {
+				c.titles[ti] = true
+			}
+		}
+	}
+
+	type scored struct {
+		Phrase
+		stems []string
+		pos   int
+	}
+	phrases := make([]scored, 0, len(candidates))
+	for _, c := range candidates {
+		weight := weightTerm
+		switch {
+		case c.entity:
+			weight = weightEntity + weightPerWord*float64(c.words-2)
+		case c.proper:
+			weight = weightProperNoun
+		}
+
+		text := bestForm(c.forms)
+		p := Phrase{
+			Text:    text,
+			Hashtag: Hashtag(text),
+			Count:   len(c.titles),
+			Entity:  c.entity,
+			Score:   float64(len(c.titles)) * corpus.IDF(c.stems) * weight,
+		}
+		if p.Hashtag != "" {
+			phrases = append(phrases, scored{Phrase: p, stems: c.stems, pos: c.firstPos})
+		}
+	}
+
+	sort.SliceStable(phrases, func(i, j int) bool {
+		if phrases[i].Score != phrases[j].Score {
+			return phrases[i].Score > phrases[j].Score
+		}
+		return phrases[i].pos < phrases[j].pos
+	})
+
+	// Drop phrases whose words are all covered by a better one ("Rodríguez" after "Delcy Rodríguez")
+	var result []Phrase
+	covered := make(map[string]bool)
+	for _, p := range phrases {
+		if len(result) == n {
+			break
+		}
+		redundant := true
+		for _, s := range p.stems {
+			if !covered[s] {
+				redundant = false
+				break
+			}
+		}
+		if redundant {
+			continue
+		}
+		for _, s := range p.stems {
+			covered[s] = true
+		}
+		result = append(result, p.Phrase)
+	}
+	return result
+}
+
+// splitWords splits a title on spaces, remembering where punctuation breaks it
+func splitWords(title string) []word {
+	var words []word
+	for _, field := range strings.Fields(title) {
+		trimmed := strings.TrimFunc(field, isEdgePunct)
+		if trimmed == "" {
+			if len(words) > 0 {
+				words[len(words)-1].breaks = true // A lone dash or quote
+			}
+			continue
+		}
+
+		w := word{
+			text:   trimmed,
+			folded: textproc.Fold(trimmed),
+			index:  len(words),
+			opens:  !strings.HasPrefix(field, trimmed),
+			breaks: !strings.HasSuffix(field, trimmed),
+		}
+		r := []rune(trimmed)
+		w.capital = unicode.IsUpper(r[0])
+		words = append(words, w)
+	}
+	return words
+}
+
+// isEdgePunct is punctuation that may surround a word ("«Boric»", "Chile:")
+func isEdgePunct(r rune) bool {
+	return unicode.IsPunct(r) || unicode.IsSymbol(r)
+}
+
+func foldedWords(words []word) []string {
+	folded := make([]string, len(words))
+	for i, w := range words {
+		folded[i] = w.folded
+	}
+	return folded
+}
+
+func capitalRatio(words []word) float64 {
+	significant, capital := 0, 0
+	for _, w := range words {
+		if connectors[w.folded] || textproc.IsStopword(w.folded) {
+			continue
+		}
+		significant++
+		if w.capital {
+			capital++
+		}
+	}
+	if significant < 4 {
+		return 0
+	}
+	return float64(capital) / float64(significant)
+}
+
+// nameRuns returns the runs of capitalized words, joined by connectors.
+// The first word of a title is capitalized anyway, so it only starts a name
+// when the next word is capitalized too, and never when it is a stopword.
+func nameRuns(words []word) [][]word {
+	var runs [][]word
+	var run []word
+
+	flush := func() {
+		// Connectors can't end a name
+		for len(run) > 0 && !run[len(run)-1].capital {
+			run = run[:len(run)-1]
+		}
+		if len(run) > 0 {
+			runs = append(runs, run)
+		}
+		run = nil
+	}
+
+	for i, w := range words {
+		if w.opens {
+			flush()
+		}
+
+		switch {
+		case w.capital && i == 0:
+			next := i+1 < len(words) && words[i+1].capital && !w.breaks
+			if next && !textproc.IsStopword(w.folded) {
+				run = append(run, w)
+			}
+		case w.capital:
+			run = append(run, w)
+		case connectors[w.folded] && len(run) > 0:
+			run = append(run, w)
+		default:
+			flush()
+		}
+
+		if w.breaks {
+			flush()
+		}
+	}
+	flush()
+	return runs
+}
+
+// phraseOf joins the run as written and returns the stems of its significant words
+func phraseOf(run []word, lang string) (string, []string) {
+	parts := make([]string, len(run))
+	var stems []string
+	for i, w := range run {
+		parts[i] = w.text
+		if !connectors[w.folded] && !textproc.IsStopword(w.folded) && hasLetter(w.folded) {
+			stems = append(stems, textproc.Stem(strings.ToLower(w.text), lang))
+		}
+	}
+	return strings.Join(parts, " "), stems
+}
+
+func foldedKey(run []word) string {
+	parts := make([]string, len(run))
+	for i, w := range run {
+		parts[i] = strings.Join(textproc.Words(w.text), " ")
+	}
+	return strings.Join(parts, " ")
+}
+
+// lowerUnlessName keeps the capitals of names and acronyms, but not of sentence-initial words
+func lowerUnlessName(w word) string {
+	if w.index == 0 && !isAcronym(w.text) {
+		return strings.ToLower(w.text)
+	}
+	return w.text
+}
+
+func isAcronym(s string) bool {
+	letters := 0
+	for _, r := range s {
+		if unicode.IsLower(r) {
+			return false
+		}
+		if unicode.IsLetter(r) {
+			letters++
+		}
+	}
+	return letters >= 2
+}
+
+func hasLetter(s string) bool {
+	for _, r := range s {
+		if unicode.IsLetter(r) {
+			return true
+		}
+	}
+	return false
+}
+
+// bestForm is the most common spelling, preferring capitalized ones on ties
+func bestForm(forms map[string]int) string {
+	best, count := "", -1
+	for form, n := range forms {
+		if n > count || n == count && form < best {
+			best, count = form, n
+		}
+	}
+	return best
+}
+
+// Hashtag turns a phrase into a hashtag: accents and punctuation removed, each
+// word capitalized and joined ("Delcy Rodríguez" -> "#DelcyRodriguez",
+// "EE.UU." -> "#EEUU"). Returns "" if nothing usable is left.
+func Hashtag(phrase string) string {
+	var b strings.Builder
+	for _, part := range strings.Fields(phrase) {
+		var clean []rune
+		for _, r := range norm.NFD.String(part) {
+			if unicode.IsLetter(r) || unicode.IsDigit(r) {
+				clean = append(clean, r)
+			}
+		}
+		if len(clean) == 0 {
+			continue
+		}
+		clean[0] = unicode.ToUpper(clean[0])
+		b.WriteString(string(clean))
+	}
+
+	tag := b.String()
+	// Mastodon rejects hashtags made only of digits
+	if !hasLetter(tag) {
+		return ""
+	}
+	return "#" + tag
+}
@@ -0,0 +1,148 @@
+package mastodon
+
+import (
+	"context"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mattn/go-mastodon"
+)
+
+// TrendCache keeps the posts of each keyword in memory so pages never wait on
+// Mastodon. Keywords are refreshed in the background when their posts are older
+// than the TTL; a keyword nobody asks for (and the refresher doesn't keep warm)
+// is dropped after a few TTLs.
+type TrendCache struct {
+	service *Service
+	ttl     time.Duration
+
+	mu      sync.RWMutex
+	entries map[string]*trendEntry
+}
+
+type trendEntry struct {
+	posts      []*mastodon.Status
+	fetchedAt  time.Time // Last attempt, successful or not
+	lastWanted time.Time
+}
+
+func NewTrendCache(service *Service) *TrendCache {
+	// Entries unwanted for 3 TTLs are dropped, so without a TTL nothing would be served
+	ttl := service.Config().CacheTTL
+	if ttl <= 0 {
+		ttl = DefaultConfig().CacheTTL
+	}
+	return &TrendCache{
+		service: service,
+		ttl:     ttl,
+		entries: make(map[string]*trendEntry),
+	}
+}
+
+func cacheKey(keyword string) string {
+	return strings.ToLower(strings.TrimSpace(keyword))
+}
+
+// Get returns the cached posts for a keyword, possibly stale, without blocking.
+// Unknown keywords return nil and are fetched on the next refresh.
+func (c *TrendCache) Get(keyword string) []*mastodon.Status {
+	key := cacheKey(keyword)
+	if key == "" {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok {
+		entry = &trendEntry{}
+		c.entries[key] = entry
+	}
+	entry.lastWanted = time.Now()
+	return entry.posts
+}
+
+// Run refreshes the cache every RefreshInterval until the context is cancelled.
+// keywords (optional) returns the keywords to keep warm, e.g. those of the top stories.
+func (c *TrendCache) Run(ctx context.Context, keywords func() []string) {
+	interval := c.service.Config().RefreshInterval
+	if interval <= 0 {
+		log.Println("⚠️  Mastodon trends disabled (MASTODON_REFRESH_INTERVAL=0)")
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		var warm []string
+		if keywords != nil {
+			warm = keywords()
+		}
+		c.Refresh(ctx, warm)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Refresh fetches the stale keywords among the given ones and those asked for
+// recently, and evicts the ones nobody wants anymore
+func (c *TrendCache) Refresh(ctx context.Context, warm []string) {
+	now := time.Now()
+
+	c.mu.Lock()
+	for _, kw := range warm {
+		key := cacheKey(kw)
+		if key == "" {
+			continue
+		}
+		if entry, ok := c.entries[key]; ok {
+			entry.lastWanted = now
+		} else {
+			c.entries[key] = &trendEntry{lastWanted: now}
+		}
+	}
+
+	var stale []string
+	for key, entry := range c.entries {
+		switch {
+		case now.Sub(entry.lastWanted) > 3*c.ttl:
+			delete(c.entries, key)
+		case now.Sub(entry.fetchedAt) >= c.ttl:
+			stale = append(stale, key)
+		}
+	}
+	c.mu.Unlock()
+
+	for _, key := range stale {
+		if ctx.Err() != nil {
+			return
+		}
+
+		posts, err := c.service.GetTrendsContext(ctx, key)
+		if err != nil {
+			log.Printf("⚠️ Mastodon fetch failed for %q: %v", key, err)
+		}
+
+		c.mu.Lock()
+		if entry, ok := c.entries[key]; ok {
+			entry.fetchedAt = time.Now()
+			// Keep serving the previous posts if the instance failed
+			if err == nil {
+				entry.posts = posts
+			}
+		}
+		c.mu.Unlock()
+	}
+
+	if len(stale) > 0 {
+		log.Printf("🐘 Refreshed Mastodon trends for %d keywords", len(stale))
+	}
+}
@@ -0,0 +1,67 @@
+package mastodon
+
+import (
+	"os"
+	"strings"
+	"time"
+
+	"vidit/internal/env"
+)
+
+// Config selects the Mastodon instance and how much the integration asks of it.
+// Every field can be set through the environment (see ConfigFromEnv).
+type Config struct {
+	Server          string        // MASTODON_SERVER
+	AccessToken     string        // MASTODON_ACCESS_TOKEN, optional (some instances require it)
+	Languages       []string      // MASTODON_LANGUAGES, e.g. "es,en"; empty accepts any language
+	Limit           int           // MASTODON_LIMIT: posts kept per keyword
+	FetchLimit      int           // MASTODON_FETCH_LIMIT: statuses requested per hashtag (max 40)
+	Timeout         time.Duration // MASTODON_TIMEOUT: per request
+	CacheTTL        time.Duration // MASTODON_CACHE_TTL: how long cached posts are fresh
+	RefreshInterval time.Duration // MASTODON_REFRESH_INTERVAL: background refresh period (0 = disabled)
+	Keywords        int           // MASTODON_KEYWORDS: top stories whose keyword is kept warm
+}
+
+// DefaultConfig is the configuration used for unset variables
+func DefaultConfig() Config {
+	return Config{
+		Server:          "https://mastodon.social",
+		Languages:       []string{"es", "en"},
+		Limit:           5,
+		FetchLimit:      40,
+		Timeout:         10 * time.Second,
+		CacheTTL:        10 * time.Minute,
+		RefreshInterval: 2 * time.Minute,
+		Keywords:        3,
+	}
+}
+
+// ConfigFromEnv reads the MASTODON_* variables over the defaults
+func ConfigFromEnv() Config {
+	cfg := DefaultConfig()
+
+	if v := strings.TrimSpace(os.Getenv("MASTODON_SERVER")); v != "" {
+		cfg.Server = strings.TrimRight(v, "/")
+	}
+	cfg.AccessToken = strings.TrimSpace(os.Getenv("MASTODON_ACCESS_TOKEN"))
+
+	if v, ok := os.LookupEnv("MASTODON_LANGUAGES"); ok {
+		cfg.Languages = nil
+		for _, lang := range strings.Split(v, ",") {
+			if lang = strings.ToLower(strings.TrimSpace(lang)); lang != "" {
+				cfg.Languages = append(cfg.Languages, lang)
+			}
+		}
+	}
+
+	cfg.Limit = env.Int("MASTODON_LIMIT", cfg.Limit, 1)
+	cfg.FetchLimit = env.Int("MASTODON_FETCH_LIMIT", cfg.FetchLimit, 1)
+	if cfg.FetchLimit > 40 {
+		cfg.FetchLimit = 40 // Mastodon's maximum page size
+	}
+	cfg.Keywords = env.Int("MASTODON_KEYWORDS", cfg.Keywords, 1)
+	cfg.Timeout = env.Duration("MASTODON_TIMEOUT", cfg.Timeout, 0)
+	cfg.CacheTTL = env.Duration("MASTODON_CACHE_TTL", cfg.CacheTTL, time.Second)
+	cfg.RefreshInterval = env.Duration("MASTODON_REFRESH_INTERVAL", cfg.RefreshInterval, 0)
+	return cfg
+}
@@ -0,0 +1,217 @@
+package mastodon
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"vidit/internal/env"
+	"vidit/internal/keyphrase"
+	"vidit/internal/models"
+
+	"github.com/mattn/go-mastodon"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	maxStatusLength  = 500 // Mastodon's default character limit
+	maxListedSources = 6
+)
+
+// PublisherConfig decides which stories the bot posts and how often.
+// Every field can be set through the environment (see PublisherConfigFromEnv).
+type PublisherConfig struct {
+	Enabled    bool          // MASTODON_PUBLISH
+	DryRun     bool          // MASTODON_PUBLISH_DRY_RUN: log and record the posts without sending them
+	MinScore   float64       // MASTODON_PUBLISH_MIN_SCORE: story gravity needed
+	MinSources int           // MASTODON_PUBLISH_MIN_SOURCES: distinct feeds covering the story
+	MaxAge     time.Duration // MASTODON_PUBLISH_MAX_AGE: only stories that emerged this recently
+	MaxPerHour int           // MASTODON_PUBLISH_MAX_PER_HOUR: rate cap, dry runs included
+	Visibility string        // MASTODON_PUBLISH_VISIBILITY: public, unlisted, private
+	Hashtags   int           // MASTODON_PUBLISH_HASHTAGS: hashtags appended to each post
+}
+
+// DefaultPublisherConfig is the configuration used for unset variables
+func DefaultPublisherConfig() PublisherConfig {
+	return PublisherConfig{
+		MinScore:   1.0,
+		MinSources: 3,
+		MaxAge:     6 * time.Hour,
+		MaxPerHour: 4,
+		Visibility: "public",
+		Hashtags:   2,
+	}
+}
+
+// PublisherConfigFromEnv reads the MASTODON_PUBLISH_* variables over the defaults
+func PublisherConfigFromEnv() PublisherConfig {
+	cfg := DefaultPublisherConfig()
+	cfg.Enabled = env.Bool("MASTODON_PUBLISH", cfg.Enabled)
+	cfg.DryRun = env.Bool("MASTODON_PUBLISH_DRY_RUN", cfg.DryRun)
+	cfg.MinScore = env.Float("MASTODON_PUBLISH_MIN_SCORE", cfg.MinScore, 0)
+	cfg.MinSources = env.Int("MASTODON_PUBLISH_MIN_SOURCES", cfg.MinSources, 1)
+	cfg.MaxAge = env.Duration("MASTODON_PUBLISH_MAX_AGE", cfg.MaxAge, 0)
+	cfg.MaxPerHour = env.Int("MASTODON_PUBLISH_MAX_PER_HOUR", cfg.MaxPerHour, 1)
+	cfg.Hashtags = env.Int("MASTODON_PUBLISH_HASHTAGS", cfg.Hashtags, 1)
+	if v := strings.TrimSpace(os.Getenv("MASTODON_PUBLISH_VISIBILITY")); v != "" {
+		cfg.Visibility = v
+	}
+	return cfg
+}
+
+// Publisher posts newly emerged, widely covered stories from the bot account
+type Publisher struct {
+	db      *gorm.DB
+	service *Service
+	config  PublisherConfig
+}
+
+func NewPublisher(db *gorm.DB, service *Service, cfg PublisherConfig) *Publisher {
+	if cfg.Enabled && !cfg.DryRun && service.Config().AccessToken == "" {
+		log.Println("⚠️  Mastodon publishing needs MASTODON_ACCESS_TOKEN, switching to dry run")
+		cfg.DryRun = true
+	}
+	return &Publisher{db: db, service: service, config: cfg}
+}
+
+// Publish posts the stories that crossed the thresholds since the last call,
+// within what the rate cap allows. It returns how many were posted.
+func (p *Publisher) Publish(ctx context.Context) (int, error) {
+	if !p.config.Enabled {
+		return 0, nil
+	}
+
+	var recent int64
+	err := p.db.Model(&models.MastodonPost{}).
+		Where("created_at > ?", time.Now().Add(-time.Hour)).
+		Count(&recent).Error
+	if err != nil {
+		return 0, err
+	}
+	budget := p.config.MaxPerHour - int(recent)
+	if budget <= 0 {
+		return 0, nil
+	}
+
+	var stories []models.Story
+	err = p.db.
+		Preload("LeadArticle.Feed").
+		Preload("Articles.Feed").
+		Where("stories.first_seen_at > ? AND stories.score >= ? AND stories.article_count >= ?",
+			time.Now().Add(-p.config.MaxAge), p.config.MinScore, p.config.MinSources).
+		Where("NOT EXISTS (SELECT 1 FROM mastodon_posts mp WHERE mp.story_id = stories.id)").
+		Order("stories.score DESC").
+		Limit(budget * 5). // Some may still fall short of distinct sources
+		Find(&stories).Error
+	if err != nil {
+		return 0, err
+	}
+
+	posted := 0
+	for _, story := range stories {
+		if posted == budget || ctx.Err() != nil {
+			break
+		}
+		if story.LeadArticle.Feed.ID == 0 || len(story.OtherSources())+1 < p.config.MinSources {
+			continue
+		}
+
+		ok, err := p.post(ctx, story)
+		if err != nil {
+			log.Printf("❌ Mastodon post failed for story %d: %v\n", story.ID, err)
+			continue
+		}
+		if ok {
+			posted++
+		}
+	}
+
+	if posted > 0 {
+		log.Printf("🐘 Published %d stories to Mastodon (dry run: %v)\n", posted, p.config.DryRun)
+	}
+	return posted, nil
+}
+
+// post claims the story in mastodon_posts before sending it, so two runs never post
+// it twice; the claim is released if the instance rejects the post
+func (p *Publisher) post(ctx context.Context, story models.Story) (bool, error) {
+	record := models.MastodonPost{
+		StoryID: story.ID,
+		Text:    ComposeStatus(story, p.config.Hashtags),
+		DryRun:  p.config.DryRun,
+	}
+
+	result := p.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&record)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return false, nil // Already posted
+	}
+
+	if p.config.DryRun {
+		log.Printf("🐘 [dry run] Would post story %d:\n%s\n", story.ID, record.Text)
+		return true, nil
+	}
+
+	status, err := p.service.client.PostStatus(ctx, &mastodon.Toot{
+		Status:     record.Text,
+		Visibility: p.config.Visibility,
+		Language:   statusLanguage(story.LeadArticle.Language),
+	})
+	if err != nil {
+		p.db.Delete(&record)
+		return false, err
+	}
+
+	p.db.Model(&record).Updates(map[string]interface{}{
+		"status_id": string(status.ID),
+		"url":       status.URL,
+	})
+	return true, nil
+}
+
+// statusLanguage is the ISO 639-1 code Mastodon expects ("es-cl" → "es");
+// empty when the source doesn't declare one, leaving detection to the instance
+func statusLanguage(lang string) string {
+	lang, _, _ = strings.Cut(strings.ToLower(strings.TrimSpace(lang)), "-")
+	return lang
+}
+
+// ComposeStatus writes the post of a story: title, sources, link and hashtags,
+// shortening the title if the post would exceed Mastodon's limit
+func ComposeStatus(story models.Story, hashtags int) string {
+	lead := story.LeadArticle
+
+	sources := []string{lead.Feed.Name}
+	titles := []string{lead.Title}
+	for _, a := range story.OtherSources() {
+		sources = append(sources, a.Feed.Name)
+		titles = append(titles, a.Title)
+	}
+
+	if len(sources) > maxListedSources {
+		sources = append(sources[:maxListedSources], fmt.Sprintf("+%d", len(sources)-maxListedSources))
+	}
+
+	var tags []string
+	for _, phrase := range keyphrase.Extract(titles, nil, hashtags) {
+		tags = append(tags, phrase.Hashtag)
+	}
+
+	rest := fmt.Sprintf("\n\n📰 %s\n🔗 %s", strings.Join(sources, ", "), lead.URL)
+	if len(tags) > 0 {
+		rest += "\n\n" + strings.Join(tags, " ")
+	}
+
+	title := []rune(lead.Title)
+	room := maxStatusLength - len([]rune(rest))
+	if len(title) > room && room > 1 {
+		title = append(title[:room-1], '…')
+	}
+	return string(title) + rest
+}
@@ -5,23 +5,43 @@ import (
 	"fmt"
 	"strings"
 
+	"vidit/internal/keyphrase"
+
 	"github.com/mattn/go-mastodon"
 )
 
 type Service struct {
 	client *mastodon.Client
+	config Config
 }
 
+// NewService connects to the instance configured in the environment
 func NewService() *Service {
-	return &Service{
-		client: mastodon.NewClient(&mastodon.Config{
-			Server: "https://mastodon.social",
-		}),
-	}
+	return NewServiceWithConfig(ConfigFromEnv())
+}
+
+func NewServiceWithConfig(cfg Config) *Service {
+	client := mastodon.NewClient(&mastodon.Config{
+		Server:      cfg.Server,
+		AccessToken: cfg.AccessToken,
+	})
+	client.Timeout = cfg.Timeout
+
+	return &Service{client: client, config: cfg}
+}
+
+// Config returns the configuration the service was created with
+func (s *Service) Config() Config {
+	return s.config
 }
 
 // GetTrends fetches recent posts for the given keywords
 func (s *Service) GetTrends(keyword string) ([]*mastodon.Status, error) {
+	return s.GetTrendsContext(context.Background(), keyword)
+}
+
+// GetTrendsContext is GetTrends with a context for cancellation
+func (s *Service) GetTrendsContext(ctx context.Context, keyword string) ([]*mastodon.Status, error) {
 	// Clean keyword
 	keyword = strings.TrimSpace(keyword)
 	if keyword == "" {
@@ -30,12 +50,12 @@ func (s *Service) GetTrends(keyword string) ([]*mastodon.Status, error) {
 
 	// Search for tag
 	// api/v1/timelines/tag/:hashtag
-	posts, err := s.client.GetTimelineHashtag(context.Background(), keyword, false, nil)
+	posts, err := s.client.GetTimelineHashtag(ctx, keyword, false, &mastodon.Pagination{Limit: int64(s.config.FetchLimit)})
 	if err != nil {
 		return nil, err
 	}
 
-	// Filter posts (No NSFW, Must have content)
+	// Filter posts (No NSFW, Must have content, configured languages)
 	var validPosts []*mastodon.Status
 	for _, p := range posts {
 		if p.Sensitive {
@@ -44,10 +64,11 @@ func (s *Service) GetTrends(keyword string) ([]*mastodon.Status, error) {
 		if p.Content == "" {
 			continue
 		}
-		// Optional: Filter non-spanish/english? Mastodon API has language filter but go-mastodon might generic.
-		// For now, accept all.
+		if !s.acceptsLanguage(p.Language) {
+			continue
+		}
 		validPosts = append(validPosts, p)
-		if len(validPosts) >= 5 {
+		if len(validPosts) >= s.config.Limit {
 			break
 		}
 	}
@@ -55,26 +76,32 @@ func (s *Service) GetTrends(keyword string) ([]*mastodon.Status, error) {
 	return validPosts, nil
 }
 
-// ExtractKeywords finds the most "interesting" word in a title (e.g. Proper Nouns or Long words)
-func ExtractKeywords(title string) string {
-	words := strings.Fields(title)
-	var candidates []string
-
-	// Strategy: Prefer Capitalized words (Proper nouns) that are not at start (unless common)
-	// Simple Fallback: Longest word > 4 chars
-	for _, w := range words {
-		clean := strings.Trim(w, ",.:;\"'()")
-		if len(clean) > 4 {
-			candidates = append(candidates, clean)
+// acceptsLanguage reports whether a post's language is one of the configured ones.
+// Posts without a language are kept, many clients don't set it.
+func (s *Service) acceptsLanguage(lang string) bool {
+	if len(s.config.Languages) == 0 || lang == "" {
+		return true
+	}
+	lang = strings.ToLower(lang)
+	for _, l := range s.config.Languages {
+		if lang == l || strings.HasPrefix(lang, l+"-") {
+			return true
 		}
 	}
+	return false
+}
 
-	// Heuristic: If we find "Venezuela", "Maduro", "Trump", return that.
-	// For now, return the longest candidate or the first one > 5 chars?
-	// Better: Use the first candidate that looks like a Noun.
+// ExtractKeywords returns the hashtag (without "#") of the most significant name or
+// term of a title. For whole stories, prefer KeywordsForStory.
+func ExtractKeywords(title string) string {
+	return KeywordsForStory([]string{title}, nil)
+}
 
-	if len(candidates) > 0 {
-		return candidates[0] // Return first significant word
+// KeywordsForStory returns the hashtag (without "#") that best describes the titles
+// of a story cluster, weighting terms against corpus when given (see keyphrase)
+func KeywordsForStory(titles []string, corpus *keyphrase.Corpus) string {
+	if phrases := keyphrase.Extract(titles, corpus, 1); len(phrases) > 0 {
+		return strings.TrimPrefix(phrases[0].Hashtag, "#")
 	}
 	return "news"
 }
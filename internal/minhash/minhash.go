@@ -0,0 +1,172 @@
+// Package minhash finds near-duplicate token sets (e.g. news titles) without comparing every pair.
+//
+// Each set gets a MinHash signature, and the signature is split into bands. Two sets land in
+// the same bucket of some band with a probability that grows quickly with their Jaccard
+// similarity, so the candidates of a query are only the sets sharing at least one bucket.
+// Callers then confirm candidates with the exact Jaccard index.
+package minhash
+
+import (
+	"hash/fnv"
+	"math"
+	"sort"
+)
+
+// With 32 bands of 2 rows, pairs at Jaccard 0.35 are found ~98.5% of the time and
+// pairs at 0.4 ~99.6%, which suits the short, low-overlap titles we compare.
+const (
+	DefaultBands = 32
+	DefaultRows  = 2
+)
+
+// Signature is the MinHash of a set: the minimum of each hash function over its tokens
+type Signature []uint64
+
+// Index is a MinHash LSH index. Items are identified by their insertion order (0, 1, 2...).
+// It is not safe for concurrent writes.
+type Index struct {
+	bands   int
+	rows    int
+	seeds   []uint64
+	buckets []map[uint64][]int
+	size    int
+}
+
+// NewIndex creates an index using bands*rows hash functions
+func NewIndex(bands, rows int) *Index {
+	if bands <= 0 {
+		bands = DefaultBands
+	}
+	if rows <= 0 {
+		rows = DefaultRows
+	}
+
+	idx := &Index{
+		bands:   bands,
+		rows:    rows,
+		seeds:   make([]uint64, bands*rows),
+		buckets: make([]map[uint64][]int, bands),
+	}
+
+	// Fixed seeds: signatures are comparable across runs and processes
+	seed := uint64(0x9e3779b97f4a7c15)
+	for i := range idx.seeds {
+		seed = splitmix64(seed)
+		idx.seeds[i] = seed
+	}
+	for b := range idx.buckets {
+		idx.buckets[b] = make(map[uint64][]int)
+	}
+	return idx
+}
+
+// Len is the number of items added
+func (idx *Index) Len() int {
+	return idx.size
+}
+
+// Sign computes the signature of a token set
+func (idx *Index) Sign(tokens map[string]bool) Signature {
+	sig := make(Signature, len(idx.seeds))
+	for i := range sig {
+		sig[i] = math.MaxUint64
+	}
+
+	for token := range tokens {
+		h := hashString(token)
+		for i, seed := range idx.seeds {
+			if v := splitmix64(h ^ seed); v < sig[i] {
+				sig[i] = v
+			}
+		}
+	}
+	return sig
+}
+
+// Insert adds a signature and returns its ID.
+// Empty sets get an ID but are never returned as candidates.
+func (idx *Index) Insert(sig Signature) int {
+	id := idx.size
+	idx.size++
+
+	if isEmpty(sig) {
+		return id
+	}
+
+	for b := 0; b < idx.bands; b++ {
+		key := idx.bandKey(sig, b)
+		idx.buckets[b][key] = append(idx.buckets[b][key], id)
+	}
+	return id
+}
+
+// Add signs and inserts a token set
+func (idx *Index) Add(tokens map[string]bool) int {
+	return idx.Insert(idx.Sign(tokens))
+}
+
+// Candidates returns, in ascending order, the IDs sharing at least one band with sig
+func (idx *Index) Candidates(sig Signature) []int {
+	if isEmpty(sig) {
+		return nil
+	}
+
+	seen := make(map[int]bool)
+	var ids []int
+	for b := 0; b < idx.bands; b++ {
+		for _, id := range idx.buckets[b][idx.bandKey(sig, b)] {
+			if !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+	}
+
+	sort.Ints(ids)
+	return ids
+}
+
+// Query signs a token set and returns its candidates
+func (idx *Index) Query(tokens map[string]bool) []int {
+	return idx.Candidates(idx.Sign(tokens))
+}
+
+// Estimate approximates the Jaccard similarity of two signatures
+func Estimate(a, b Signature) float64 {
+	if len(a) == 0 || len(a) != len(b) {
+		return 0
+	}
+	equal := 0
+	for i := range a {
+		if a[i] == b[i] {
+			equal++
+		}
+	}
+	return float64(equal) / float64(len(a))
+}
+
+func (idx *Index) bandKey(sig Signature, band int) uint64 {
+	key := uint64(band)
+	for _, v := range sig[band*idx.rows : (band+1)*idx.rows] {
+		key = splitmix64(key ^ v)
+	}
+	return key
+}
+
+func isEmpty(sig Signature) bool {
+	return len(sig) == 0 || sig[0] == math.MaxUint64
+}
+
+func hashString(s string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(s))
+	return h.Sum64()
+}
+
+// splitmix64 is a fast, well-distributed 64-bit mixer
+func splitmix64(x uint64) uint64 {
+	x += 0x9e3779b97f4a7c15
+	x = (x ^ (x >> 30)) * 0xbf58476d1ce4e5b9
+	x = (x ^ (x >> 27)) * 0x94d049bb133111eb
+	return x ^ (x >> 31)
+}
@@ -18,6 +18,16 @@ type Article struct {
 	PublishedAt time.Time `gorm:"index" json:"published_at"`
 	Score       float64   `gorm:"default:1.0;index" json:"score"`
 
+	// Metadata from the feed item, HTML stripped
+	Summary    string   `gorm:"type:text" json:"summary"`
+	Author     string   `json:"author"`
+	ImageURL   string   `json:"image_url"`
+	Categories []string `gorm:"serializer:json" json:"categories"`
+	Language   string   `gorm:"size:16" json:"language,omitempty"` // As declared by the source, e.g. "es" or "es-cl"
+
+	// Number of similar articles seen so far; kept so scores can be re-decayed without re-clustering
+	ClusterCount int `gorm:"default:0" json:"cluster_count"`
+
 	// Foreign Key
 	FeedID uint `gorm:"not null;index" json:"feed_id"`
 	Feed   Feed `gorm:"foreignKey:FeedID" json:"feed"`
@@ -15,12 +15,41 @@ type Feed struct {
 
 	Name          string     `gorm:"not null" json:"name"`
 	URL           string     `gorm:"not null;unique" json:"url"`
-	Type          string     `gorm:"default:'rss'" json:"type"`         // rss or sitemap
+	Type          string     `gorm:"default:'rss'" json:"type"`         // A registered source: rss, atom, sitemap, newsapi, scrape
 	Category      string     `gorm:"default:'general'" json:"category"` // cybersecurity, international, latam, usa, china
 	Country       string     `gorm:"default:'int'" json:"country"`      // CL, ES, US, INT
 	ColorHex      string     `gorm:"default:#3b82f6" json:"color_hex"`  // Default blue
 	LastFetchedAt *time.Time `json:"last_fetched_at"`
 
+	// Sources tried in order when the type's fails. nil uses the type's default
+	// chain, an empty list disables fallbacks.
+	Fallbacks []string `gorm:"serializer:json" json:"fallbacks"`
+	// Adopt the type and URL of a fallback that succeeds (recorded in FeedTypeChange)
+	AutoSwitchType bool `gorm:"default:false" json:"auto_switch_type"`
+	// Selectors of scrape feeds
+	ScrapeRules *ScrapeRules `gorm:"serializer:json" json:"scrape_rules,omitempty"`
+
+	// Editorial trust: multiplies the source weight in the gravity formula (1 = neutral)
+	TrustWeight float64 `gorm:"default:1" json:"trust_weight"`
+
+	// HTTP cache validators of the last successful response (conditional GET)
+	ETag         string `gorm:"column:etag" json:"-"`
+	LastModified string `json:"-"`
+
+	// Scheduling (adaptive polling)
+	FetchIntervalMinutes int        `gorm:"default:15" json:"fetch_interval_minutes"`
+	NextFetchAt          *time.Time `gorm:"index" json:"next_fetch_at"`
+
+	// Health, kept out of the JSON API: errors can quote the sources' responses.
+	// The admin health dashboard shows it.
+	ConsecutiveFailures int        `gorm:"default:0" json:"-"`
+	LastError           string     `json:"-"`
+	LastSuccessAt       *time.Time `json:"-"`
+	LastFailureAt       *time.Time `json:"-"`
+	DisabledAt          *time.Time `gorm:"index" json:"disabled_at"` // Set when auto-disabled after too many failures
+
 	// Relationships
-	Articles []Article `gorm:"foreignKey:FeedID" json:"-"`
+	Articles    []Article        `gorm:"foreignKey:FeedID" json:"-"`
+	FetchLogs   []FeedFetchLog   `gorm:"foreignKey:FeedID" json:"-"`
+	TypeChanges []FeedTypeChange `gorm:"foreignKey:FeedID" json:"-"`
 }
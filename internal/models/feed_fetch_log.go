@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// FeedFetchLog records a single fetch attempt (one strategy) for a feed
+type FeedFetchLog struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `gorm:"index" json:"created_at"`
+
+	Strategy    string `gorm:"not null" json:"strategy"` // rss, newsapi, sitemap
+	URL         string `json:"url"`
+	StatusCode  int    `json:"status_code"` // 0 when no HTTP response was received
+	LatencyMs   int64  `json:"latency_ms"`
+	ItemCount   int    `json:"item_count"`
+	NotModified bool   `json:"not_modified"` // 304 answer to a conditional request
+	Success     bool   `gorm:"index" json:"success"`
+	Error       string `json:"error"`
+
+	// Foreign Key
+	FeedID uint `gorm:"not null;index" json:"feed_id"`
+}
@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// FeedTypeChange records a feed switching to the fallback source that worked for it
+type FeedTypeChange struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `gorm:"index" json:"created_at"`
+
+	FromType string `json:"from_type"`
+	ToType   string `json:"to_type"`
+	FromURL  string `json:"from_url"`
+	ToURL    string `json:"to_url"`
+	Reason   string `json:"reason"` // Error of the source that was replaced
+
+	// Foreign Key
+	FeedID uint `gorm:"not null;index" json:"feed_id"`
+}
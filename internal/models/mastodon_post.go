@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// MastodonPost remembers which stories the bot has published, so each is posted once.
+// Dry runs are recorded too (DryRun), and count towards the rate cap.
+type MastodonPost struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `gorm:"index" json:"created_at"`
+
+	StoryID  uint   `gorm:"not null;uniqueIndex" json:"story_id"`
+	StatusID string `json:"status_id"` // Empty for dry runs
+	URL      string `json:"url"`
+	Text     string `gorm:"type:text" json:"text"`
+	DryRun   bool   `json:"dry_run"`
+}
@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// RankingConfig holds the parameters of the gravity ranking and the title clustering.
+// The table has a single row (ID 1) shared by the server and every command.
+type RankingConfig struct {
+	ID        uint      `gorm:"primarykey" json:"-"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	WeightRSS     float64 `json:"weight_rss"`
+	WeightSitemap float64 `json:"weight_sitemap"`
+	WeightAPI     float64 `json:"weight_api"`
+
+	WeightCluster float64 `json:"weight_cluster"`
+	GravityDecay  float64 `json:"gravity_decay"`
+
+	ThresholdCluster float64 `json:"threshold_cluster"` // Similarity that counts as "same topic" for scoring
+	ThresholdDedup   float64 `json:"threshold_dedup"`   // Similarity that groups articles into one story
+
+	// Added to the source weight of every feed of a country (e.g. "CL") or category (e.g. "cybersecurity")
+	CountryBoosts  map[string]float64 `gorm:"serializer:json" json:"country_boosts"`
+	CategoryBoosts map[string]float64 `gorm:"serializer:json" json:"category_boosts"`
+}
@@ -0,0 +1,14 @@
+package models
+
+// ScrapeRules tell the scrape source where the headlines of a front page are.
+// Selectors are CSS, matched inside each Item.
+type ScrapeRules struct {
+	Item       string `json:"item"`                  // Each headline block, e.g. "article" or "div.nota"
+	Title      string `json:"title"`                 // Its text is the headline, e.g. "h2"
+	Link       string `json:"link,omitempty"`        // Element whose href is the article; defaults to the title's link
+	Date       string `json:"date,omitempty"`        // Read from datetime/content attributes, or its text
+	DateFormat string `json:"date_format,omitempty"` // Go layout for text dates, e.g. "02/01/2006 15:04"
+	Timezone   string `json:"timezone,omitempty"`    // For dates without a zone, e.g. "America/Santiago" (UTC if empty)
+	Summary    string `json:"summary,omitempty"`
+	Image      string `json:"image,omitempty"` // Its src, data-src or content attribute
+}
@@ -0,0 +1,59 @@
+package models
+
+import (
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Story groups the articles from different feeds that cover the same event
+type Story struct {
+	ID        uint           `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+
+	Title        string    `gorm:"not null" json:"title"` // Title of the lead article
+	Score        float64   `gorm:"default:0;index" json:"score"`
+	ArticleCount int       `gorm:"default:1" json:"article_count"`
+	FirstSeenAt  time.Time `gorm:"index" json:"first_seen_at"`
+	LastSeenAt   time.Time `gorm:"index" json:"last_seen_at"`
+
+	// The highest scored article of the story, shown on the card
+	LeadArticleID uint    `gorm:"index" json:"lead_article_id"`
+	LeadArticle   Article `gorm:"foreignKey:LeadArticleID" json:"lead_article"`
+
+	// Relationships
+	Articles []Article `gorm:"many2many:story_articles" json:"articles,omitempty"`
+}
+
+// StoryArticle is the join table between stories and articles.
+// An article belongs to at most one story.
+type StoryArticle struct {
+	StoryID   uint      `gorm:"primaryKey" json:"story_id"`
+	ArticleID uint      `gorm:"primaryKey;uniqueIndex" json:"article_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// OtherSources returns one article per feed that covered the story, excluding the lead's feed.
+// Articles whose feed was soft-deleted (not preloaded) are skipped.
+func (s Story) OtherSources() []Article {
+	seen := map[uint]bool{s.LeadArticle.FeedID: true}
+
+	sorted := make([]Article, len(s.Articles))
+	copy(sorted, s.Articles)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Score > sorted[j].Score
+	})
+
+	var others []Article
+	for _, a := range sorted {
+		if a.Feed.ID == 0 || seen[a.FeedID] {
+			continue
+		}
+		seen[a.FeedID] = true
+		others = append(others, a)
+	}
+	return others
+}
@@ -0,0 +1,45 @@
+package textproc
+
+import "strings"
+
+// stemEnglish is a light English stemmer: plurals and the most common
+// verb/adverb endings, in the spirit of Porter step 1. English titles are a
+// minority, so it favours merging obvious variants over linguistic precision.
+func stemEnglish(w string) string {
+	if len(w) <= 3 {
+		return w
+	}
+
+	// Plurals
+	switch {
+	case strings.HasSuffix(w, "sses"):
+		w = w[:len(w)-2]
+	case strings.HasSuffix(w, "ies") && len(w) > 4:
+		w = w[:len(w)-3] + "y"
+	case strings.HasSuffix(w, "s") && !strings.HasSuffix(w, "ss") && !strings.HasSuffix(w, "us") && !strings.HasSuffix(w, "is"):
+		w = w[:len(w)-1]
+	}
+
+	// Verb and adverb endings, only when a vowel remains in the stem
+	for _, suffix := range []string{"ingly", "edly", "ing", "ed", "ly"} {
+		if !strings.HasSuffix(w, suffix) {
+			continue
+		}
+		stem := w[:len(w)-len(suffix)]
+		if len(stem) < 3 || !strings.ContainsAny(stem, "aeiouy") {
+			break
+		}
+		// "stopped" -> "stop"
+		if n := len(stem); stem[n-1] == stem[n-2] && !strings.ContainsRune("lsz", rune(stem[n-1])) {
+			stem = stem[:n-1]
+		}
+		w = stem
+		break
+	}
+
+	// Let "elected" and "election" meet halfway with "elect"
+	if strings.HasSuffix(w, "ion") && len(w) > 5 && (w[len(w)-4] == 't' || w[len(w)-4] == 's') {
+		w = w[:len(w)-3]
+	}
+	return w
+}
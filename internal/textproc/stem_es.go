@@ -0,0 +1,300 @@
+package textproc
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// stemSpanish follows the Snowball Spanish stemmer. It works on lowercase words
+// with their accents, which tell verb endings apart ("anuncia" is not "-ía"),
+// and returns the stem folded. Regions are byte offsets into the UTF-8 word.
+// See https://snowballstem.org/algorithms/spanish/stemmer.html
+func stemSpanish(word string) string {
+	if utf8.RuneCountInString(word) < MinTokenLength {
+		return Fold(word)
+	}
+
+	rv, r1, r2 := spanishRegions(word)
+
+	// Step 0: attached pronouns (e.g. "diciendolo" -> "diciendo")
+	word = spanishPronoun(word, rv)
+
+	// Step 1: standard suffixes
+	stemmed, changed := spanishStandardSuffix(word, r1, r2)
+	word = stemmed
+
+	// Step 2: verb suffixes, only when step 1 removed nothing
+	if !changed {
+		if stemmed, ok := spanishYVerbSuffix(word, rv); ok {
+			word = stemmed
+		} else {
+			word = spanishVerbSuffix(word, rv)
+		}
+	}
+
+	// Step 3: residual suffix, then the remaining accents go
+	return Fold(spanishResidualSuffix(word, rv))
+}
+
+func isSpanishVowel(r rune) bool {
+	switch r {
+	case 'a', 'e', 'i', 'o', 'u', 'á', 'é', 'í', 'ó', 'ú', 'ü':
+		return true
+	}
+	return false
+}
+
+// spanishRegions computes RV, R1 and R2 as byte offsets
+func spanishRegions(w string) (rv, r1, r2 int) {
+	runes := []rune(w)
+	n := len(runes)
+
+	// offsets[i] is the byte offset of rune i; offsets[n] is len(w)
+	offsets := make([]int, 0, n+1)
+	for i := range w {
+		offsets = append(offsets, i)
+	}
+	offsets = append(offsets, len(w))
+
+	rvRune := n
+	if n >= 2 {
+		switch {
+		case !isSpanishVowel(runes[1]):
+			// Region after the next vowel following the second letter
+			for i := 2; i < n; i++ {
+				if isSpanishVowel(runes[i]) {
+					rvRune = i + 1
+					break
+				}
+			}
+		case isSpanishVowel(runes[0]):
+			// Two vowels: region after the next consonant
+			for i := 2; i < n; i++ {
+				if !isSpanishVowel(runes[i]) {
+					rvRune = i + 1
+					break
+				}
+			}
+		default:
+			rvRune = 3
+		}
+	}
+	if rvRune > n {
+		rvRune = n
+	}
+
+	r1Rune := regionAfterVC(runes, 0)
+	r2Rune := regionAfterVC(runes, r1Rune)
+	return offsets[rvRune], offsets[r1Rune], offsets[r2Rune]
+}
+
+// regionAfterVC is the region (a rune index) after the first non-vowel following
+// a vowel, starting at from
+func regionAfterVC(runes []rune, from int) int {
+	for i := from + 1; i < len(runes); i++ {
+		if !isSpanishVowel(runes[i]) && isSpanishVowel(runes[i-1]) {
+			return i + 1
+		}
+	}
+	return len(runes)
+}
+
+// longestSuffix returns the longest of the suffixes that w ends with
+func longestSuffix(w string, suffixes []string) string {
+	best := ""
+	for _, s := range suffixes {
+		if len(s) > len(best) && strings.HasSuffix(w, s) {
+			best = s
+		}
+	}
+	return best
+}
+
+// in reports whether the suffix (of length n) starts inside the region
+func in(w string, n, region int) bool {
+	return len(w)-n >= region
+}
+
+var spanishPronouns = []string{"me", "se", "sela", "selo", "selas", "selos", "la", "le", "lo", "las", "les", "los", "nos"}
+
+// Verb endings accented before a pronoun, with their plain form
+var spanishAccentedVerbs = [][2]string{{"iéndo", "iendo"}, {"ándo", "ando"}, {"ár", "ar"}, {"ér", "er"}, {"ír", "ir"}}
+
+func spanishPronoun(w string, rv int) string {
+	p := longestSuffix(w, spanishPronouns)
+	if p == "" || !in(w, len(p), rv) {
+		return w
+	}
+
+	before := w[:len(w)-len(p)]
+
+	// The accent the pronoun required goes with it ("diciéndolo" -> "diciendo")
+	for _, verb := range spanishAccentedVerbs {
+		if strings.HasSuffix(before, verb[0]) && in(before, len(verb[0]), rv) {
+			return before[:len(before)-len(verb[0])] + verb[1]
+		}
+	}
+	for _, verb := range []string{"iendo", "ando", "ar", "er", "ir"} {
+		// Headlines have far more "-ernos" nouns (gobiernos, internos) than "-er" + "nos" verbs
+		if verb == "er" && p == "nos" {
+			continue
+		}
+		if strings.HasSuffix(before, verb) && in(before, len(verb), rv) {
+			return before
+		}
+	}
+	if strings.HasSuffix(before, "uyendo") && in(before, len("yendo"), rv) {
+		return before
+	}
+	return w
+}
+
+var spanishStep1 = []string{
+	"anza", "anzas", "ico", "ica", "icos", "icas", "ismo", "ismos", "able", "ables", "ible", "ibles",
+	"ista", "istas", "oso", "osa", "osos", "osas", "amiento", "amientos", "imiento", "imientos",
+	"adora", "ador", "ación", "adoras", "adores", "aciones", "ante", "antes", "ancia", "ancias",
+	"logía", "logías", "ución", "uciones", "encia", "encias", "amente", "mente",
+	"idad", "idades", "iva", "ivo", "ivas", "ivos",
+}
+
+func spanishStandardSuffix(w string, r1, r2 int) (string, bool) {
+	s := longestSuffix(w, spanishStep1)
+	if s == "" {
+		return w, false
+	}
+	stem := w[:len(w)-len(s)]
+
+	// Deletes the preceding suffix too when it also lies in R2
+	dropR2 := func(stem string, preceding ...string) string {
+		for _, p := range preceding {
+			if strings.HasSuffix(stem, p) && in(stem, len(p), r2) {
+				return stem[:len(stem)-len(p)]
+			}
+		}
+		return stem
+	}
+
+	switch s {
+	case "adora", "ador", "ación", "adoras", "adores", "aciones", "ante", "antes", "ancia", "ancias":
+		if !in(w, len(s), r2) {
+			return w, false
+		}
+		return dropR2(stem, "ic"), true
+
+	case "logía", "logías":
+		if !in(w, len(s), r2) {
+			return w, false
+		}
+		return stem + "log", true
+
+	case "ución", "uciones":
+		if !in(w, len(s), r2) {
+			return w, false
+		}
+		return stem + "u", true
+
+	case "encia", "encias":
+		if !in(w, len(s), r2) {
+			return w, false
+		}
+		return stem + "ente", true
+
+	case "amente":
+		if !in(w, len(s), r1) {
+			return w, false
+		}
+		if strings.HasSuffix(stem, "iv") && in(stem, 2, r2) {
+			return dropR2(stem[:len(stem)-2], "at"), true
+		}
+		return dropR2(stem, "os", "ic", "ad"), true
+
+	case "mente":
+		if !in(w, len(s), r2) {
+			return w, false
+		}
+		return dropR2(stem, "ante", "able", "ible"), true
+
+	case "idad", "idades":
+		if !in(w, len(s), r2) {
+			return w, false
+		}
+		return dropR2(stem, "abil", "ic", "iv"), true
+
+	case "iva", "ivo", "ivas", "ivos":
+		if !in(w, len(s), r2) {
+			return w, false
+		}
+		return dropR2(stem, "at"), true
+	}
+
+	// anza, ico, ismo, able, ista, oso, amiento...
+	if !in(w, len(s), r2) {
+		return w, false
+	}
+	return stem, true
+}
+
+var spanishStep2a = []string{"ya", "ye", "yan", "yen", "yeron", "yendo", "yo", "yó", "yas", "yes", "yais", "yamos"}
+
+// spanishYVerbSuffix removes verb suffixes beginning with y, when preceded by u
+func spanishYVerbSuffix(w string, rv int) (string, bool) {
+	s := longestSuffix(w, spanishStep2a)
+	if s == "" || !in(w, len(s), rv) {
+		return w, false
+	}
+	stem := w[:len(w)-len(s)]
+	if !strings.HasSuffix(stem, "u") {
+		return w, false
+	}
+	return stem, true
+}
+
+var spanishStep2b = []string{
+	"en", "es", "éis", "emos",
+	"arían", "arías", "arán", "arás", "aríais", "aría", "aréis", "aríamos", "aremos", "ará", "aré",
+	"erían", "erías", "erán", "erás", "eríais", "ería", "eréis", "eríamos", "eremos", "erá", "eré",
+	"irían", "irías", "irán", "irás", "iríais", "iría", "iréis", "iríamos", "iremos", "irá", "iré",
+	"aba", "ada", "ida", "ía", "ara", "iera", "ad", "ed", "id", "ase", "iese", "aste", "iste", "an",
+	"aban", "ían", "aran", "ieran", "asen", "iesen", "aron", "ieron", "ado", "ido", "ando", "iendo",
+	"ió", "ar", "er", "ir", "as", "abas", "adas", "idas", "ías", "aras", "ieras", "ases", "ieses", "ís",
+	"áis", "abais", "íais", "arais", "ierais", "aseis", "ieseis", "asteis", "isteis", "ados",
+	"idos", "amos", "ábamos", "íamos", "imos", "áramos", "iéramos", "iésemos", "ásemos",
+}
+
+func spanishVerbSuffix(w string, rv int) string {
+	s := longestSuffix(w, spanishStep2b)
+	if s == "" || !in(w, len(s), rv) {
+		return w
+	}
+	stem := w[:len(w)-len(s)]
+
+	switch s {
+	case "en", "es", "éis", "emos":
+		// "gu" + e: the u goes too ("persiguen" -> "persig")
+		if strings.HasSuffix(stem, "gu") {
+			stem = stem[:len(stem)-1]
+		}
+	}
+	return stem
+}
+
+func spanishResidualSuffix(w string, rv int) string {
+	switch s := longestSuffix(w, []string{"os", "a", "o", "á", "í", "ó", "e", "é"}); s {
+	case "":
+		return w
+	case "e", "é":
+		if !in(w, len(s), rv) {
+			return w
+		}
+		stem := w[:len(w)-len(s)]
+		if strings.HasSuffix(stem, "gu") && in(stem, 1, rv) {
+			stem = stem[:len(stem)-1]
+		}
+		return stem
+	default:
+		if !in(w, len(s), rv) {
+			return w
+		}
+		return w[:len(w)-len(s)]
+	}
+}
@@ -0,0 +1,39 @@
+package textproc
+
+// IsStopword reports whether a folded word is a Spanish or English stopword
+func IsStopword(word string) bool {
+	return spanishStopwords[word] || englishStopwords[word]
+}
+
+// Folded (no accents), so they match the output of Words
+var spanishStopwords = setOf(
+	"a", "al", "algo", "algun", "alguna", "algunas", "alguno", "algunos", "ante", "antes", "aqui",
+	"asi", "aun", "aunque", "bajo", "bien", "cada", "casi", "como", "con", "contra", "cual",
+	"cuales", "cuando", "cuanto", "de", "del", "desde", "donde", "dos", "durante", "e", "el",
+	"ella", "ellas", "ello", "ellos", "en", "entre", "era", "eran", "es", "esa", "esas", "ese",
+	"eso", "esos", "esta", "estaba", "estan", "estar", "este", "esto", "estos", "fue",
+	"fueron", "ha", "habia", "han", "hasta", "hay", "hace", "hacia", "le", "les", "lo", "los",
+	"la", "las", "mas", "me", "mi", "mientras", "mismo", "muy", "nada", "ni", "no", "nos",
+	"nosotros", "o", "otra", "otras", "otro", "otros", "para", "pero", "poco", "por", "porque",
+	"que", "quien", "quienes", "se", "sea", "segun", "ser", "si", "sido", "sin", "sobre", "son",
+	"su", "sus", "tal", "tambien", "tan", "tanto", "te", "tiene", "tienen", "todo", "todos",
+	"tras", "tu", "un", "una", "unas", "uno", "unos", "va", "van", "y", "ya", "yo",
+)
+
+var englishStopwords = setOf(
+	"a", "about", "after", "all", "also", "an", "and", "any", "are", "as", "at", "be", "been",
+	"before", "being", "but", "by", "can", "could", "did", "do", "does", "for", "from", "had",
+	"has", "have", "he", "her", "his", "how", "i", "if", "in", "into", "is", "it", "its",
+	"more", "most", "new", "not", "of", "on", "or", "other", "our", "out", "over", "says",
+	"she", "so", "than", "that", "the", "their", "them", "then", "there", "these", "they",
+	"this", "those", "to", "under", "up", "was", "we", "were", "what", "when", "where", "which",
+	"while", "who", "why", "will", "with", "would", "you", "your",
+)
+
+func setOf(words ...string) map[string]bool {
+	set := make(map[string]bool, len(words))
+	for _, w := range words {
+		set[w] = true
+	}
+	return set
+}
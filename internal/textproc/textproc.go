@@ -0,0 +1,111 @@
+// Package textproc normalizes Spanish and English text for matching: accent folding,
+// stopword removal and stemming. Clustering, dedup, search and keyword extraction
+// all go through it, so "Presidenta Rodríguez" and "presidente Rodriguez" agree.
+package textproc
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Languages understood by Stem and the stopword lists
+const (
+	Spanish = "es"
+	English = "en"
+)
+
+// MinTokenLength is the shortest word (in runes) kept by Tokens
+const MinTokenLength = 3
+
+// Fold lowercases s and strips diacritics ("Rodríguez" -> "rodriguez", "pingüino" -> "pinguino").
+// The tilde of ñ is kept, since "año" and "ano" are different words.
+func Fold(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+
+	var prev rune
+	for _, r := range norm.NFD.String(s) {
+		if unicode.Is(unicode.Mn, r) {
+			if r == '̃' && (prev == 'n' || prev == 'N') {
+				b.WriteRune(r)
+			}
+			continue
+		}
+		b.WriteRune(unicode.ToLower(r))
+		prev = r
+	}
+
+	return norm.NFC.String(b.String())
+}
+
+// Words splits folded text into words of letters and digits
+func Words(s string) []string {
+	return strings.FieldsFunc(Fold(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// lowerWords splits lowercased text into words, keeping their accents for the stemmer
+func lowerWords(s string) []string {
+	return strings.FieldsFunc(norm.NFC.String(strings.ToLower(s)), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// Tokens returns the stems of the significant words of s: without stopwords or
+// short words, stemmed in the detected language and folded
+func Tokens(s string) []string {
+	words := lowerWords(s)
+	folded := make([]string, len(words))
+	for i, w := range words {
+		folded[i] = Fold(w)
+	}
+	lang := DetectLanguage(folded)
+
+	tokens := make([]string, 0, len(words))
+	for i, w := range words {
+		if len([]rune(folded[i])) < MinTokenLength || IsStopword(folded[i]) {
+			continue
+		}
+		tokens = append(tokens, Stem(w, lang))
+	}
+	return tokens
+}
+
+// TokenSet is Tokens as a set
+func TokenSet(s string) map[string]bool {
+	set := make(map[string]bool)
+	for _, t := range Tokens(s) {
+		set[t] = true
+	}
+	return set
+}
+
+// DetectLanguage guesses between Spanish and English from the stopwords among folded words.
+// Spanish wins ties, since most sources are Spanish.
+func DetectLanguage(words []string) string {
+	es, en := 0, 0
+	for _, w := range words {
+		if spanishStopwords[w] {
+			es++
+		}
+		if englishStopwords[w] {
+			en++
+		}
+	}
+	if en > es {
+		return English
+	}
+	return Spanish
+}
+
+// Stem reduces a lowercase word to its folded stem in the given language. Spanish
+// needs the accents ("anuncia" vs "anunciaría"), so words are stemmed before folding.
+func Stem(word, lang string) string {
+	if lang == English {
+		return stemEnglish(Fold(word))
+	}
+	return stemSpanish(norm.NFC.String(word))
+}